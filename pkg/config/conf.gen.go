@@ -7,7 +7,10 @@ type Cloudflare struct {
 	ApiKey string `mapstructure:"api-key"`
 	ApiToken string `mapstructure:"api-token"`
 	AccountId string `mapstructure:"account-id"`
+	AccountIds []string `mapstructure:"account-ids"`
+	SkipAccountIds []string `mapstructure:"skip-account-ids"`
 	EmailId string `mapstructure:"email-id"`
+	RevokeAccessSessionsOnDelete bool `mapstructure:"revoke-access-sessions-on-delete"`
 	BaseUrl string `mapstructure:"base-url"`
 }
 
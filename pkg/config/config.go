@@ -22,8 +22,17 @@ var (
 	accountIdField = field.StringField(
 		"account-id",
 		field.WithDisplayName("Account ID"),
-		field.WithDescription("The account id for the Cloudflare account."),
-		field.WithRequired(true),
+		field.WithDescription("The account id for the Cloudflare account. Leave empty to sync every account the credentials can access."),
+	)
+	accountIdsField = field.StringSliceField(
+		"account-ids",
+		field.WithDisplayName("Account IDs"),
+		field.WithDescription("Additional Cloudflare account ids to sync. When set, only these accounts (and the account id, if provided) are synced."),
+	)
+	skipAccountIdsField = field.StringSliceField(
+		"skip-account-ids",
+		field.WithDisplayName("Skipped Account IDs"),
+		field.WithDescription("Cloudflare account ids to exclude from the sync."),
 	)
 	emailIdField = field.StringField(
 		"email-id",
@@ -31,6 +40,11 @@ var (
 		field.WithDescription("The email id for the Cloudflare account."),
 		field.WithRequired(true),
 	)
+	revokeAccessSessionsOnDeleteField = field.BoolField(
+		"revoke-access-sessions-on-delete",
+		field.WithDisplayName("Revoke Access sessions on delete"),
+		field.WithDescription("Revoke a user's Zero Trust Access sessions when they are removed from an account."),
+	)
 	baseUrlField = field.StringField(
 		"base-url",
 		field.WithDescription("Override the Cloudflare API URL (for testing)"),
@@ -41,7 +55,10 @@ var (
 		apiKeyField,
 		apiTokenField,
 		accountIdField,
+		accountIdsField,
+		skipAccountIdsField,
 		emailIdField,
+		revokeAccessSessionsOnDeleteField,
 		baseUrlField,
 	}
 )
@@ -57,14 +74,14 @@ var Config = field.NewConfiguration(
 			Name:        "api-token-group",
 			DisplayName: "API Token",
 			HelpText:    "Use an API token for authentication.",
-			Fields:      []field.SchemaField{accountIdField, apiTokenField},
+			Fields:      []field.SchemaField{accountIdField, apiTokenField, accountIdsField, skipAccountIdsField, revokeAccessSessionsOnDeleteField},
 			Default:     true,
 		},
 		{
 			Name:        "api-key-group",
 			DisplayName: "Email + API key",
 			HelpText:    "Use an API key with email for authentication.",
-			Fields:      []field.SchemaField{accountIdField, emailIdField, apiKeyField},
+			Fields:      []field.SchemaField{accountIdField, emailIdField, apiKeyField, accountIdsField, skipAccountIdsField, revokeAccessSessionsOnDeleteField},
 		},
 	}),
 )
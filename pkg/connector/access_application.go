@@ -0,0 +1,500 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
+)
+
+const (
+	accessApplicationAccessEntitlement = "access"
+	accessApplicationsPerPage          = 50
+	accessPolicyDecisionAllow          = "allow"
+)
+
+type accessApplicationResourceType struct {
+	resourceType *v2.ResourceType
+	client       *cloudflare.API
+	accountId    string
+}
+
+func (o *accessApplicationResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+// accessRuleEmailDomain returns the domain of an Access email domain rule, {"email_domain": {"domain": "..."}}.
+func accessRuleEmailDomain(rule interface{}) (string, bool) {
+	ruleMap, ok := rule.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	domainRule, ok := ruleMap["email_domain"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	domain, ok := domainRule["domain"].(string)
+	if !ok || domain == "" {
+		return "", false
+	}
+
+	return domain, true
+}
+
+// accessRuleGroupID returns the Access group referenced by a group rule, {"group": {"id": "..."}}.
+func accessRuleGroupID(rule interface{}) (string, bool) {
+	ruleMap, ok := rule.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	groupRule, ok := ruleMap["group"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	groupID, ok := groupRule["id"].(string)
+	if !ok || groupID == "" {
+		return "", false
+	}
+
+	return groupID, true
+}
+
+// emailInDomain reports whether the address belongs to the domain.
+func emailInDomain(email, domain string) bool {
+	return strings.HasSuffix(strings.ToLower(email), "@"+strings.ToLower(strings.TrimPrefix(domain, "@")))
+}
+
+// accessRuleIsEveryone reports whether the rule is the {"everyone": {}} rule, which matches anyone.
+func accessRuleIsEveryone(rule interface{}) bool {
+	ruleMap, ok := rule.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = ruleMap["everyone"]
+
+	return ok
+}
+
+// accessMatch is how far the connector can tell whether Access policies let a user in.
+type accessMatch int
+
+const (
+	accessMatchNone accessMatch = iota
+	// accessMatchUnnarrowed means the user is included, but the policy has require or exclude
+	// rules the connector cannot evaluate, such as IdP groups or IP ranges, that may keep them out.
+	accessMatchUnnarrowed
+	accessMatchFull
+)
+
+// accessRuleMatches reports whether the rule matches the address, and whether the connector could
+// evaluate it at all. Everyone, email and email domain rules are always evaluated. A group rule is
+// evaluated only when the group is made of email include rules alone; other rules, such as IdP
+// groups or IP ranges, cannot be resolved to users.
+func accessRuleMatches(rule interface{}, email string, groups map[string]cloudflare.AccessGroup) (bool, bool) {
+	if accessRuleIsEveryone(rule) {
+		return true, true
+	}
+	if ruleEmail, ok := accessRuleEmail(rule); ok {
+		return strings.EqualFold(ruleEmail, email), true
+	}
+	if domain, ok := accessRuleEmailDomain(rule); ok {
+		return emailInDomain(email, domain), true
+	}
+	if groupID, ok := accessRuleGroupID(rule); ok {
+		group, found := groups[groupID]
+		if !found {
+			return false, false
+		}
+		groupEmails := accessGroupEmails(group)
+		if slices.ContainsFunc(groupEmails, func(e string) bool { return strings.EqualFold(e, email) }) {
+			return true, true
+		}
+		onlyEmails := len(groupEmails) == len(group.Include) && len(group.Exclude) == 0 && len(group.Require) == 0
+		return false, onlyEmails
+	}
+
+	return false, false
+}
+
+// accessPolicyRules are the rules of one Access policy. Cloudflare evaluates each policy on its
+// own: a user must match an include rule and every require rule, and no exclude rule.
+type accessPolicyRules struct {
+	include []interface{}
+	require []interface{}
+	exclude []interface{}
+}
+
+// grantsGroups reports whether the policy's Access groups are granted as groups. Only a policy
+// without require or exclude rules lets in every group member; otherwise the members are evaluated
+// one by one so the policy's other rules narrow them.
+func (p accessPolicyRules) grantsGroups() bool {
+	return len(p.require) == 0 && len(p.exclude) == 0
+}
+
+// groupIDs returns the Access groups granted as groups by the policy's include rules.
+func (p accessPolicyRules) groupIDs() []string {
+	if !p.grantsGroups() {
+		return nil
+	}
+
+	var rv []string
+	for _, rule := range p.include {
+		if groupID, ok := accessRuleGroupID(rule); ok && !slices.Contains(rv, groupID) {
+			rv = append(rv, groupID)
+		}
+	}
+
+	return rv
+}
+
+// match evaluates the policy for the address: include, then require, then exclude.
+func (p accessPolicyRules) match(email string, groups map[string]cloudflare.AccessGroup) accessMatch {
+	included := false
+	for _, rule := range p.include {
+		if _, ok := accessRuleGroupID(rule); ok && p.grantsGroups() {
+			// Granted to the group instead, and expanded to its members.
+			continue
+		}
+		if matched, _ := accessRuleMatches(rule, email, groups); matched {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return accessMatchNone
+	}
+
+	rv := accessMatchFull
+	for _, rule := range p.require {
+		matched, evaluated := accessRuleMatches(rule, email, groups)
+		if !evaluated {
+			rv = accessMatchUnnarrowed
+			continue
+		}
+		if !matched {
+			return accessMatchNone
+		}
+	}
+	for _, rule := range p.exclude {
+		matched, evaluated := accessRuleMatches(rule, email, groups)
+		if !evaluated {
+			rv = accessMatchUnnarrowed
+			continue
+		}
+		if matched {
+			return accessMatchNone
+		}
+	}
+
+	return rv
+}
+
+// accessPolicyAudience is who a set of Access policies lets in. A user is let in when any one
+// policy lets them in.
+type accessPolicyAudience struct {
+	policies []accessPolicyRules
+	// groups are the account's Access groups, used to evaluate group rules user by user.
+	groups map[string]cloudflare.AccessGroup
+}
+
+// add adds a policy to the audience.
+func (a *accessPolicyAudience) add(policy cloudflare.AccessPolicy) {
+	a.policies = append(a.policies, accessPolicyRules{
+		include: policy.Include,
+		require: policy.Require,
+		exclude: policy.Exclude,
+	})
+}
+
+// accessAllowAudience collects the allow policies. Other decisions do not grant access.
+func accessAllowAudience(policies []cloudflare.AccessPolicy) *accessPolicyAudience {
+	audience := &accessPolicyAudience{}
+	for _, policy := range policies {
+		if strings.EqualFold(policy.Decision, accessPolicyDecisionAllow) {
+			audience.add(policy)
+		}
+	}
+
+	return audience
+}
+
+// match returns the best match of any policy for the address.
+func (a *accessPolicyAudience) match(email string) accessMatch {
+	rv := accessMatchNone
+	for _, policy := range a.policies {
+		if m := policy.match(email, a.groups); m > rv {
+			rv = m
+		}
+	}
+
+	return rv
+}
+
+// allows reports whether a policy certainly lets the address in.
+func (a *accessPolicyAudience) allows(email string) bool {
+	return a.match(email) == accessMatchFull
+}
+
+// grantOptions returns the options of a grant to the address, and false when no policy lets it in.
+func (a *accessPolicyAudience) grantOptions(email string) ([]grant.GrantOption, bool) {
+	grantOpts := []grant.GrantOption{grant.WithAnnotation(&v2.GrantImmutable{})}
+	switch a.match(email) {
+	case accessMatchNone:
+		return nil, false
+	case accessMatchUnnarrowed:
+		grantOpts = append(grantOpts, grant.WithGrantMetadata(map[string]interface{}{"unnarrowed": true}))
+	}
+
+	return grantOpts, true
+}
+
+// groupIDs returns the Access groups the policies grant as groups.
+func (a *accessPolicyAudience) groupIDs() []string {
+	var rv []string
+	for _, policy := range a.policies {
+		for _, groupID := range policy.groupIDs() {
+			if !slices.Contains(rv, groupID) {
+				rv = append(rv, groupID)
+			}
+		}
+	}
+
+	return rv
+}
+
+// usesGroups reports whether any rule evaluated user by user refers to an Access group.
+func (a *accessPolicyAudience) usesGroups() bool {
+	for _, policy := range a.policies {
+		rules := slices.Concat(policy.require, policy.exclude)
+		if !policy.grantsGroups() {
+			rules = append(rules, policy.include...)
+		}
+		for _, rule := range rules {
+			if _, ok := accessRuleGroupID(rule); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchesUsers reports whether any include rule is evaluated user by user.
+func (a *accessPolicyAudience) matchesUsers() bool {
+	for _, policy := range a.policies {
+		for _, rule := range policy.include {
+			if _, ok := accessRuleGroupID(rule); !ok || !policy.grantsGroups() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func accessApplicationResource(app cloudflare.AccessApplication, resourceID string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"app_id":           app.ID,
+		"app_name":         app.Name,
+		"app_type":         string(app.Type),
+		"domain":           app.Domain,
+		"aud":              app.AUD,
+		"session_duration": app.SessionDuration,
+	}
+
+	displayName := app.Name
+	if displayName == "" {
+		displayName = app.Domain
+	}
+	if displayName == "" {
+		displayName = app.ID
+	}
+
+	opts := []rs.ResourceOption{
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithResourceProfile(profile),
+		rs.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: resourceTypeAccessPolicy.Id}),
+	}
+	if app.CreatedAt != nil {
+		opts = append(opts, rs.WithResourceCreatedAt(*app.CreatedAt))
+	}
+
+	return rs.NewAppResource(
+		displayName,
+		resourceTypeAccessApplication,
+		resourceID,
+		nil,
+		opts...,
+	)
+}
+
+func (o *accessApplicationResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	page, err := convertPageToken(opts.PageToken.Token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: invalid page token: %w", err)
+	}
+
+	apps, _, err := o.client.ListAccessApplications(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.ListAccessApplicationsParams{
+		ResultInfo: cloudflare.ResultInfo{Page: page, PerPage: accessApplicationsPerPage},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: failed to list access applications: %w", err)
+	}
+
+	rv := make([]*v2.Resource, 0, len(apps))
+	for _, app := range apps {
+		ar, err := accessApplicationResource(app, accountScopedID(o.accountId, accountID, app.ID), parentResourceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		rv = append(rv, ar)
+	}
+
+	nextPage := convertNextPageToken(page, len(apps))
+
+	return rv, &rs.SyncOpResults{NextPageToken: nextPage}, nil
+}
+
+// Entitlements returns the read-only access entitlement. Access is granted through the
+// application's Access policies, so it is reviewed here but not provisioned.
+func (o *accessApplicationResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
+	rv := []*v2.Entitlement{
+		ent.NewPermissionEntitlement(
+			resource,
+			accessApplicationAccessEntitlement,
+			ent.WithGrantableTo(resourceTypeUser, resourceTypeAccessUser, resourceTypeAccessGroup),
+			ent.WithDisplayName(
+				fmt.Sprintf("%s Access", resource.DisplayName),
+			),
+			ent.WithDescription(
+				fmt.Sprintf("Is allowed to reach the %s Access application by its allow policies", resource.DisplayName),
+			),
+			ent.WithAnnotation(&v2.EntitlementImmutable{}),
+		),
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// Grants evaluates each of the application's allow policies on its own. Account members and Access
+// users a policy lets in are granted access directly; Access groups named by a policy without require or exclude
+// rules are granted access and expanded to their members.
+func (o *accessApplicationResourceType) Grants(ctx context.Context, resource *v2.Resource, opts rs.SyncOpAttrs) ([]*v2.Grant, *rs.SyncOpResults, error) {
+	accountID, appID := parseAccountScopedID(o.accountId, resource.Id.Resource)
+
+	policies, _, err := o.client.ListAccessPolicies(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.ListAccessPoliciesParams{
+		ApplicationID: appID,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: failed to list access policies: %w", err)
+	}
+
+	rv, err := accessAudienceGrants(ctx, o.client, opts.Session, o.accountId, accountID, resource, accessApplicationAccessEntitlement, accessAllowAudience(policies))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// accessAudienceGrants grants the entitlement to the account members and Access users the audience
+// lets in, and to the Access groups its policies grant as groups; group grants expand to the
+// groups' members. Users let in only if rules the connector cannot evaluate allow it are granted
+// with unnarrowed set in the grant metadata, so reviewers can tell them apart.
+func accessAudienceGrants(
+	ctx context.Context,
+	client *cloudflare.API,
+	ss sessions.SessionStore,
+	defaultAccountID string,
+	accountID string,
+	resource *v2.Resource,
+	entitlement string,
+	audience *accessPolicyAudience,
+) ([]*v2.Grant, error) {
+	var rv []*v2.Grant
+	if audience.matchesUsers() {
+		if audience.usesGroups() {
+			groups, err := accessGroupsSnapshot(ctx, client, ss, accountID)
+			if err != nil {
+				return nil, err
+			}
+			audience.groups = groups
+		}
+
+		members, err := accountMembersSnapshot(ctx, client, ss, accountID)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			// Pending invitations have no User.ID yet.
+			if member.User.ID == "" {
+				continue
+			}
+
+			grantOpts, ok := audience.grantOptions(member.User.Email)
+			if !ok {
+				continue
+			}
+
+			ur, err := userResource(member, accountScopedID(defaultAccountID, accountID, member.User.ID), accountResourceID(accountID))
+			if err != nil {
+				return nil, wrapError(err, "failed to create user resource")
+			}
+			rv = append(rv, grant.NewGrant(resource, entitlement, ur.Id, grantOpts...))
+		}
+
+		// Access users who reach the application through an identity provider need not be
+		// account members at all.
+		accessUsers, err := accessUsersSnapshot(ctx, client, ss, accountID)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range accessUsers {
+			grantOpts, ok := audience.grantOptions(user.Email)
+			if !ok {
+				continue
+			}
+			rv = append(rv, grant.NewGrant(resource, entitlement, accessUserResourceID(defaultAccountID, accountID, user), grantOpts...))
+		}
+	}
+
+	for _, groupID := range audience.groupIDs() {
+		groupResourceID := &v2.ResourceId{
+			ResourceType: resourceTypeAccessGroup.Id,
+			Resource:     accountScopedID(defaultAccountID, accountID, groupID),
+		}
+		rv = append(rv, grant.NewGrant(
+			resource,
+			entitlement,
+			groupResourceID,
+			grant.WithAnnotation(
+				&v2.GrantImmutable{},
+				&v2.GrantExpandable{
+					EntitlementIds: []string{ent.NewEntitlementID(&v2.Resource{Id: groupResourceID}, accessGroupMemberEntitlement)},
+				},
+			),
+		))
+	}
+
+	return rv, nil
+}
+
+func accessApplicationBuilder(client *cloudflare.API, accountId string) *accessApplicationResourceType {
+	return &accessApplicationResourceType{
+		resourceType: resourceTypeAccessApplication,
+		client:       client,
+		accountId:    accountId,
+	}
+}
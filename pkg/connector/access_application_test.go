@@ -0,0 +1,175 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessAllowAudience(t *testing.T) {
+	body := `[
+		{
+			"id": "policy-1",
+			"decision": "allow",
+			"include": [
+				{"email": {"email": "Jane@example.com"}},
+				{"email_domain": {"domain": "corp.example.com"}},
+				{"group": {"id": "group-1"}}
+			],
+			"exclude": [
+				{"email": {"email": "contractor@corp.example.com"}}
+			]
+		},
+		{
+			"id": "policy-2",
+			"decision": "deny",
+			"include": [
+				{"email": {"email": "blocked@example.com"}},
+				{"group": {"id": "group-2"}}
+			]
+		},
+		{
+			"id": "policy-3",
+			"decision": "allow",
+			"include": [
+				{"group": {"id": "group-1"}}
+			]
+		}
+	]`
+
+	var policies []cloudflare.AccessPolicy
+	require.NoError(t, json.Unmarshal([]byte(body), &policies))
+
+	audience := accessAllowAudience(policies)
+	assert.Equal(t, []string{"group-1"}, audience.groupIDs())
+	assert.True(t, audience.allows("jane@example.com"))
+	assert.True(t, audience.allows("sam@corp.example.com"))
+	assert.False(t, audience.allows("contractor@corp.example.com"))
+	assert.False(t, audience.allows("blocked@example.com"))
+	assert.False(t, audience.allows("sam@notcorp.example.com"))
+
+	// An everyone rule lets in anyone; policy-1's exclude does not apply to another policy.
+	audience.add(cloudflare.AccessPolicy{Decision: "allow", Include: []interface{}{map[string]interface{}{"everyone": map[string]interface{}{}}}})
+	assert.True(t, audience.allows("sam@notcorp.example.com"))
+	assert.True(t, audience.allows("contractor@corp.example.com"))
+}
+
+func TestAccessAllowAudiencePerPolicy(t *testing.T) {
+	body := `[
+		{
+			"id": "everyone-at-corp",
+			"decision": "allow",
+			"include": [{"everyone": {}}],
+			"require": [{"email_domain": {"domain": "corp.example.com"}}]
+		},
+		{
+			"id": "contractors",
+			"decision": "allow",
+			"include": [{"email_domain": {"domain": "vendor.example.com"}}],
+			"exclude": [{"email": {"email": "jane@corp.example.com"}}]
+		},
+		{
+			"id": "idp-gated",
+			"decision": "allow",
+			"include": [{"email": {"email": "sam@partner.example.com"}}],
+			"require": [{"okta": {"name": "Engineering", "identity_provider_id": "idp-1"}}]
+		}
+	]`
+
+	var policies []cloudflare.AccessPolicy
+	require.NoError(t, json.Unmarshal([]byte(body), &policies))
+	audience := accessAllowAudience(policies)
+
+	// The require rule narrows the everyone rule to the corp domain.
+	assert.Equal(t, accessMatchNone, audience.match("someone@example.org"))
+	// An exclude in the contractors policy does not remove a user the first policy lets in.
+	assert.Equal(t, accessMatchFull, audience.match("jane@corp.example.com"))
+	assert.Equal(t, accessMatchFull, audience.match("pat@vendor.example.com"))
+	// An IdP group require rule cannot be evaluated, so the user is only unnarrowed.
+	assert.Equal(t, accessMatchUnnarrowed, audience.match("sam@partner.example.com"))
+	assert.False(t, audience.allows("sam@partner.example.com"))
+}
+
+func TestAccessAllowAudienceGroups(t *testing.T) {
+	body := `[
+		{
+			"id": "engineering",
+			"decision": "allow",
+			"include": [{"group": {"id": "group-eng"}}]
+		},
+		{
+			"id": "ops-without-contractors",
+			"decision": "allow",
+			"include": [{"group": {"id": "group-ops"}}],
+			"exclude": [{"group": {"id": "group-contractors"}}]
+		}
+	]`
+
+	var policies []cloudflare.AccessPolicy
+	require.NoError(t, json.Unmarshal([]byte(body), &policies))
+	audience := accessAllowAudience(policies)
+	audience.groups = map[string]cloudflare.AccessGroup{
+		"group-ops": {ID: "group-ops", Include: []interface{}{
+			accessEmailRule("ops@example.com"),
+			accessEmailRule("temp@example.com"),
+		}},
+		"group-contractors": {ID: "group-contractors", Include: []interface{}{accessEmailRule("temp@example.com")}},
+	}
+
+	// Only the group of the policy without require or exclude rules is granted as a group.
+	assert.Equal(t, []string{"group-eng"}, audience.groupIDs())
+	assert.True(t, audience.usesGroups())
+	// Members of the excluded-from policy's group are evaluated one by one.
+	assert.Equal(t, accessMatchFull, audience.match("ops@example.com"))
+	assert.Equal(t, accessMatchNone, audience.match("temp@example.com"))
+
+	// A group whose membership is not only email rules cannot narrow anyone.
+	audience.groups["group-contractors"] = cloudflare.AccessGroup{ID: "group-contractors", Include: []interface{}{
+		map[string]interface{}{"email_domain": map[string]interface{}{"domain": "vendor.example.com"}},
+	}}
+	assert.Equal(t, accessMatchUnnarrowed, audience.match("ops@example.com"))
+}
+
+func TestAccessApplicationResource(t *testing.T) {
+	app := cloudflare.AccessApplication{ID: "app-1", Domain: "grafana.internal", Type: cloudflare.SelfHosted}
+
+	resource, err := accessApplicationResource(app, app.ID, accountResourceID("acct-1"))
+	require.NoError(t, err)
+	assert.Equal(t, resourceTypeAccessApplication.GetId(), resource.GetId().GetResourceType())
+	assert.Equal(t, "grafana.internal", resource.GetDisplayName())
+	assert.Equal(t, "app-1", resource.GetProfile().GetFields()["app_id"].GetStringValue())
+}
+
+// Access users let in by a policy are granted access even when they are not account members.
+func TestAccessAudienceGrantsAccessUsers(t *testing.T) {
+	ctx := context.Background()
+	ss := newMapSessionStore()
+	require.NoError(t, setMemberSnapshot(ctx, ss, "acct-1", [][]cloudflare.AccountMember{{
+		{ID: "member-1", User: cloudflare.AccountMemberUserDetails{ID: "user-1", Email: "jane@example.com"}},
+	}}))
+	require.NoError(t, setAccessUserSnapshot(ctx, ss, "acct-1", [][]cloudflare.AccessUser{{
+		{ID: "access-user-1", Email: "jane@example.com"},
+		{ID: "access-user-2", Email: "sam@example.com"},
+		{ID: "access-user-3", Email: "sam@partner.com"},
+	}}))
+
+	audience := accessAllowAudience([]cloudflare.AccessPolicy{{
+		Decision: "allow",
+		Include:  []interface{}{map[string]interface{}{"email_domain": map[string]interface{}{"domain": "example.com"}}},
+	}})
+	resource, err := accessApplicationResource(cloudflare.AccessApplication{ID: "app-1", Name: "Wiki"}, "app-1", accountResourceID("acct-1"))
+	require.NoError(t, err)
+
+	grants, err := accessAudienceGrants(ctx, nil, ss, "acct-1", "acct-1", resource, accessApplicationAccessEntitlement, audience)
+	require.NoError(t, err)
+
+	var principals []string
+	for _, g := range grants {
+		principals = append(principals, g.GetPrincipal().GetId().GetResourceType()+":"+g.GetPrincipal().GetId().GetResource())
+	}
+	assert.ElementsMatch(t, []string{"user:user-1", "access_user:access-user-1", "access_user:access-user-2"}, principals)
+}
@@ -0,0 +1,413 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	accessAuditLogFeedID = "access_audit_log"
+	// accessAuditLogsPerPage is the number of login events read from each account per call.
+	accessAuditLogsPerPage = 500
+	// accessAuditLogAccountsTTL is how long the list of streamed accounts is reused between polls.
+	accessAuditLogAccountsTTL = time.Hour
+	// accessAuditLogUsersTTL is how long an account's Access users are reused between polls. A login
+	// by an email missing from the list refreshes it sooner, but at most once per
+	// accessAuditLogUsersRefreshInterval, since logins by people who are not Access users never match.
+	accessAuditLogUsersTTL             = 15 * time.Minute
+	accessAuditLogUsersRefreshInterval = time.Minute
+)
+
+// accessAuditLogCursor is the stream cursor: for each account, the time of the newest login event
+// emitted and the ray IDs of the events at that time. Events are read from that time onward, so
+// the ray IDs keep events sharing the boundary timestamp from being emitted twice. The audit log
+// API filters on whole seconds, so the page to read from that second is kept as well.
+type accessAuditLogCursor struct {
+	Accounts map[string]accessAuditLogAccountCursor `json:"accounts"`
+}
+
+type accessAuditLogAccountCursor struct {
+	Since  time.Time `json:"since"`
+	RayIDs []string  `json:"ray_ids,omitempty"`
+	// Page is the page of events to read from Since's second; zero means the first.
+	Page int `json:"page,omitempty"`
+}
+
+// page returns the page of events to read next, counting from one.
+func (c accessAuditLogAccountCursor) page() int {
+	return max(c.Page, 1)
+}
+
+func parseAccessAuditLogCursor(token string) (*accessAuditLogCursor, error) {
+	cursor := &accessAuditLogCursor{Accounts: map[string]accessAuditLogAccountCursor{}}
+	if token == "" {
+		return cursor, nil
+	}
+	if err := json.Unmarshal([]byte(token), cursor); err != nil {
+		return nil, fmt.Errorf("baton-cloudflare: invalid access audit log cursor: %w", err)
+	}
+	if cursor.Accounts == nil {
+		cursor.Accounts = map[string]accessAuditLogAccountCursor{}
+	}
+
+	return cursor, nil
+}
+
+// advance returns the records not yet emitted and moves the account cursor past them. Records
+// must be in ascending order of creation.
+func (c accessAuditLogAccountCursor) advance(records []cloudflare.AccessAuditLogRecord) ([]cloudflare.AccessAuditLogRecord, accessAuditLogAccountCursor) {
+	next := accessAuditLogAccountCursor{Since: c.Since, RayIDs: slices.Clone(c.RayIDs), Page: c.Page}
+	rv := make([]cloudflare.AccessAuditLogRecord, 0, len(records))
+	for _, record := range records {
+		if record.CreatedAt == nil {
+			continue
+		}
+		createdAt := *record.CreatedAt
+		if createdAt.Before(c.Since) || (createdAt.Equal(c.Since) && slices.Contains(c.RayIDs, record.RayID)) {
+			continue
+		}
+
+		rv = append(rv, record)
+		switch {
+		case createdAt.After(next.Since):
+			next.Since = createdAt
+			next.RayIDs = []string{record.RayID}
+		case createdAt.Equal(next.Since):
+			next.RayIDs = append(next.RayIDs, record.RayID)
+		}
+	}
+
+	return rv, next
+}
+
+// advancePage advances the cursor over the page of records read at its page. The audit log API
+// filters on whole seconds, so when the cursor is still in the second it read from, a full page
+// means more events may share that second: the next read continues with the following page rather
+// than reading the first again. A short page is read again, since later events of the second may
+// still arrive. Once the cursor moves on to a later second, reading starts over at its first page.
+func (c accessAuditLogAccountCursor) advancePage(records []cloudflare.AccessAuditLogRecord, pageSize int) ([]cloudflare.AccessAuditLogRecord, accessAuditLogAccountCursor) {
+	rv, next := c.advance(records)
+	switch {
+	case !next.Since.Truncate(time.Second).Equal(c.Since.Truncate(time.Second)):
+		next.Page = 0
+	case len(records) >= pageSize:
+		next.Page = c.page() + 1
+	}
+
+	return rv, next
+}
+
+// accessAuditLogUsers is an account's Access user IDs keyed by lowercased email, and when they were
+// listed.
+type accessAuditLogUsers struct {
+	ids      map[string]string
+	listedAt time.Time
+}
+
+// accessAuditLogFeed streams Access login events as usage events: each event's actor is the Access
+// user who logged in and its target is the Access application they logged in to.
+type accessAuditLogFeed struct {
+	client         *cloudflare.API
+	accountId      string
+	accountIds     []string
+	skipAccountIds []string
+
+	// The accounts and their Access users are cached between polls.
+	mu                 sync.Mutex
+	cachedAccountIDs   []string
+	accountIDsListedAt time.Time
+	accessUsers        map[string]accessAuditLogUsers
+}
+
+func (f *accessAuditLogFeed) EventFeedMetadata(_ context.Context) *v2.EventFeedMetadata {
+	return &v2.EventFeedMetadata{
+		Id:                  accessAuditLogFeedID,
+		SupportedEventTypes: []v2.EventType{v2.EventType_EVENT_TYPE_USAGE},
+	}
+}
+
+// ListEvents reads the login events of every synced account since the cursor, or since
+// earliestEvent on the first call. More events remain while any account returns a full page.
+func (f *accessAuditLogFeed) ListEvents(
+	ctx context.Context,
+	earliestEvent *timestamppb.Timestamp,
+	pToken *pagination.StreamToken,
+) ([]*v2.Event, *pagination.StreamState, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	var token string
+	if pToken != nil {
+		token = pToken.Cursor
+	}
+	cursor, err := parseAccessAuditLogCursor(token)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	accountIDs, err := f.streamedAccountIDs(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var rv []*v2.Event
+	hasMore := false
+	for _, accountID := range accountIDs {
+		accountCursor, ok := cursor.Accounts[accountID]
+		if !ok && earliestEvent != nil {
+			accountCursor.Since = earliestEvent.AsTime()
+		}
+
+		records, err := f.listAccessAuditLogs(ctx, accountID, accountCursor)
+		if err != nil {
+			var authzErr *cloudflare.AuthorizationError
+			if errors.As(err, &authzErr) {
+				l.Warn(
+					"baton-cloudflare: not authorized to read access audit logs, skipping account",
+					zap.String("account_id", accountID),
+					zap.Error(err),
+				)
+				continue
+			}
+			return nil, nil, nil, err
+		}
+		if len(records) >= accessAuditLogsPerPage {
+			hasMore = true
+		}
+
+		records, cursor.Accounts[accountID] = accountCursor.advancePage(records, accessAuditLogsPerPage)
+		if len(records) == 0 {
+			continue
+		}
+
+		accessUserIDs, err := f.cachedAccessUserIDs(ctx, accountID, records)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, record := range records {
+			event, err := f.accessAuditLogEvent(accountID, record, accessUserIDs)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			rv = append(rv, event)
+		}
+	}
+
+	nextCursor, err := json.Marshal(cursor)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("baton-cloudflare: failed to encode access audit log cursor: %w", err)
+	}
+
+	return rv, &pagination.StreamState{Cursor: string(nextCursor), HasMore: hasMore}, nil, nil
+}
+
+// listAccessAuditLogs calls GET /accounts/{account_id}/access/logs/access-requests for the page of
+// events the cursor is at, oldest first. cloudflare-go's AccessAuditLogs cannot ask for a page, so
+// the request is made raw.
+func (f *accessAuditLogFeed) listAccessAuditLogs(ctx context.Context, accountID string, cursor accessAuditLogAccountCursor) ([]cloudflare.AccessAuditLogRecord, error) {
+	q := url.Values{}
+	q.Set("direction", "asc")
+	q.Set("limit", strconv.Itoa(accessAuditLogsPerPage))
+	q.Set("per_page", strconv.Itoa(accessAuditLogsPerPage))
+	q.Set("page", strconv.Itoa(cursor.page()))
+	if !cursor.Since.IsZero() {
+		q.Set("since", cursor.Since.UTC().Format(time.RFC3339))
+	}
+	endpoint := fmt.Sprintf("/accounts/%s/access/logs/access-requests?%s", accountID, q.Encode())
+
+	resp, err := f.client.Raw(ctx, http.MethodGet, endpoint, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("baton-cloudflare: failed to list access audit logs: %w", err)
+	}
+
+	var records []cloudflare.AccessAuditLogRecord
+	if err := json.Unmarshal(resp.Result, &records); err != nil {
+		return nil, fmt.Errorf("baton-cloudflare: failed to decode access audit logs: %w", err)
+	}
+
+	return records, nil
+}
+
+// accessAuditLogEvent builds the usage event for a login. Logins to the Access organization itself
+// have no application, and logins by emails that are not Access users have no actor. The login's
+// outcome, identity provider and source IP are attached as a details annotation; blocked logins
+// have allowed set to false.
+func (f *accessAuditLogFeed) accessAuditLogEvent(accountID string, record cloudflare.AccessAuditLogRecord, accessUserIDs map[string]string) (*v2.Event, error) {
+	usage := &v2.UsageEvent{}
+	if record.AppUID != "" {
+		usage.TargetResource = &v2.Resource{
+			Id: &v2.ResourceId{
+				ResourceType: resourceTypeAccessApplication.Id,
+				Resource:     accountScopedID(f.accountId, accountID, record.AppUID),
+			},
+			DisplayName: record.AppDomain,
+		}
+	}
+	if accessUserID, ok := accessUserIDs[strings.ToLower(record.UserEmail)]; ok {
+		usage.ActorResource = &v2.Resource{
+			Id: &v2.ResourceId{
+				ResourceType: resourceTypeAccessUser.Id,
+				Resource:     accountScopedID(f.accountId, accountID, accessUserID),
+			},
+			DisplayName: record.UserEmail,
+		}
+	}
+
+	details, err := structpb.NewStruct(map[string]interface{}{
+		"account_id": accountID,
+		"user_email": record.UserEmail,
+		"app_uid":    record.AppUID,
+		"app_domain": record.AppDomain,
+		"action":     record.Action,
+		"allowed":    record.Allowed,
+		"idp":        record.Connection,
+		"ip_address": record.IPAddress,
+		"ray_id":     record.RayID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("baton-cloudflare: failed to build access audit log details: %w", err)
+	}
+
+	return &v2.Event{
+		Id:          record.RayID,
+		OccurredAt:  timestamppb.New(*record.CreatedAt),
+		Event:       &v2.Event_UsageEvent{UsageEvent: usage},
+		Annotations: annotations.New(details),
+	}, nil
+}
+
+// streamedAccountIDs returns the streamed accounts, listing them again once the cached list is
+// older than accessAuditLogAccountsTTL.
+func (f *accessAuditLogFeed) streamedAccountIDs(ctx context.Context) ([]string, error) {
+	now := time.Now()
+	if f.cachedAccountIDs != nil && now.Sub(f.accountIDsListedAt) < accessAuditLogAccountsTTL {
+		return f.cachedAccountIDs, nil
+	}
+
+	accountIDs, err := f.listAccountIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f.cachedAccountIDs = accountIDs
+	f.accountIDsListedAt = now
+
+	return accountIDs, nil
+}
+
+// cachedAccessUserIDs returns the account's Access user IDs keyed by email for the records. The
+// users are listed again when the cached list has expired, or when a record's email is missing
+// from it and it was not listed within accessAuditLogUsersRefreshInterval: new Access users are
+// created by their first login, so their first events would otherwise have no actor.
+func (f *accessAuditLogFeed) cachedAccessUserIDs(ctx context.Context, accountID string, records []cloudflare.AccessAuditLogRecord) (map[string]string, error) {
+	now := time.Now()
+	cached, ok := f.accessUsers[accountID]
+	if ok && !cached.stale(now, records) {
+		return cached.ids, nil
+	}
+
+	ids, err := f.accessUserIDsByEmail(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if f.accessUsers == nil {
+		f.accessUsers = make(map[string]accessAuditLogUsers)
+	}
+	f.accessUsers[accountID] = accessAuditLogUsers{ids: ids, listedAt: now}
+
+	return ids, nil
+}
+
+// stale reports whether the users need to be listed again before mapping the records.
+func (u accessAuditLogUsers) stale(now time.Time, records []cloudflare.AccessAuditLogRecord) bool {
+	age := now.Sub(u.listedAt)
+	if age >= accessAuditLogUsersTTL {
+		return true
+	}
+	if age < accessAuditLogUsersRefreshInterval {
+		return false
+	}
+	for _, record := range records {
+		if _, ok := u.ids[strings.ToLower(record.UserEmail)]; !ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// accessUserIDsByEmail maps the lowercased emails of the account's Access users to their IDs.
+func (f *accessAuditLogFeed) accessUserIDsByEmail(ctx context.Context, accountID string) (map[string]string, error) {
+	users, _, err := f.client.ListAccessUsers(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.AccessUserParams{})
+	if err != nil {
+		return nil, fmt.Errorf("baton-cloudflare: failed to list access users: %w", err)
+	}
+
+	rv := make(map[string]string, len(users))
+	for _, user := range users {
+		rv[strings.ToLower(user.Email)] = user.ID
+	}
+
+	return rv, nil
+}
+
+// listAccountIDs returns the accounts whose logins are streamed: the same accounts the sync covers.
+func (f *accessAuditLogFeed) listAccountIDs(ctx context.Context) ([]string, error) {
+	if len(f.accountIds) > 0 {
+		rv := make([]string, 0, len(f.accountIds))
+		for _, accountID := range f.accountIds {
+			if !slices.Contains(f.skipAccountIds, accountID) {
+				rv = append(rv, accountID)
+			}
+		}
+		return rv, nil
+	}
+
+	var rv []string
+	for page := 1; ; page++ {
+		accounts, _, err := f.client.Accounts(ctx, cloudflare.AccountsListParams{
+			PaginationOptions: cloudflare.PaginationOptions{Page: page, PerPage: accountsPerPage},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("baton-cloudflare: could not retrieve accounts: %w", err)
+		}
+		for _, account := range accounts {
+			if !slices.Contains(f.skipAccountIds, account.ID) {
+				rv = append(rv, account.ID)
+			}
+		}
+		if len(accounts) < accountsPerPage {
+			break
+		}
+	}
+
+	return rv, nil
+}
+
+func accessAuditLogFeedBuilder(client *cloudflare.API, accountId string, accountIds, skipAccountIds []string) *accessAuditLogFeed {
+	return &accessAuditLogFeed{
+		client:         client,
+		accountId:      accountId,
+		accountIds:     accountIds,
+		skipAccountIds: skipAccountIds,
+	}
+}
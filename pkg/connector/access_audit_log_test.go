@@ -0,0 +1,188 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestAccessAuditLogCursorAdvance(t *testing.T) {
+	t1 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+	records := []cloudflare.AccessAuditLogRecord{
+		{RayID: "ray-1", CreatedAt: &t1},
+		{RayID: "ray-2", CreatedAt: &t1},
+		{RayID: "ray-3", CreatedAt: &t2},
+	}
+
+	emitted, cursor := accessAuditLogAccountCursor{}.advance(records)
+	assert.Len(t, emitted, 3)
+	assert.Equal(t, t2, cursor.Since)
+	assert.Equal(t, []string{"ray-3"}, cursor.RayIDs)
+
+	// Reading again from the cursor returns the boundary event, which must not be emitted twice.
+	t3 := t2.Add(time.Minute)
+	emitted, cursor = cursor.advance([]cloudflare.AccessAuditLogRecord{
+		{RayID: "ray-3", CreatedAt: &t2},
+		{RayID: "ray-4", CreatedAt: &t2},
+		{RayID: "ray-5", CreatedAt: &t3},
+	})
+	require.Len(t, emitted, 2)
+	assert.Equal(t, "ray-4", emitted[0].RayID)
+	assert.Equal(t, t3, cursor.Since)
+
+	parsed, err := parseAccessAuditLogCursor("")
+	require.NoError(t, err)
+	assert.Empty(t, parsed.Accounts)
+}
+
+func TestAccessAuditLogEvent(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	record := cloudflare.AccessAuditLogRecord{
+		UserEmail:  "Jane@example.com",
+		IPAddress:  "198.51.100.7",
+		AppUID:     "app-1",
+		AppDomain:  "grafana.internal",
+		Action:     "login",
+		Connection: "onetimepin",
+		Allowed:    false,
+		CreatedAt:  &createdAt,
+		RayID:      "ray-1",
+	}
+
+	feed := accessAuditLogFeedBuilder(nil, "acct-1", nil, nil)
+	event, err := feed.accessAuditLogEvent("acct-2", record, map[string]string{"jane@example.com": "access-user-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "ray-1", event.GetId())
+
+	usage := event.GetUsageEvent()
+	assert.Equal(t, "acct-2/app-1", usage.GetTargetResource().GetId().GetResource())
+	assert.Equal(t, resourceTypeAccessUser.GetId(), usage.GetActorResource().GetId().GetResourceType())
+	assert.Equal(t, "acct-2/access-user-1", usage.GetActorResource().GetId().GetResource())
+
+	details := &structpb.Struct{}
+	require.NoError(t, event.GetAnnotations()[0].UnmarshalTo(details))
+	assert.Equal(t, false, details.AsMap()["allowed"])
+	assert.Equal(t, "onetimepin", details.AsMap()["idp"])
+
+	assert.Equal(t, []v2.EventType{v2.EventType_EVENT_TYPE_USAGE}, feed.EventFeedMetadata(t.Context()).GetSupportedEventTypes())
+}
+
+// A full page of events sharing the cursor's second would be read again forever, since the API
+// filters on whole seconds; the cursor reads the next page of that second instead.
+func TestAccessAuditLogCursorFullPageBoundary(t *testing.T) {
+	second := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	records := make([]cloudflare.AccessAuditLogRecord, 0, accessAuditLogsPerPage)
+	for i := 0; i < accessAuditLogsPerPage; i++ {
+		createdAt := second.Add(time.Duration(i) * time.Millisecond)
+		records = append(records, cloudflare.AccessAuditLogRecord{RayID: fmt.Sprintf("ray-%d", i), CreatedAt: &createdAt})
+	}
+
+	// The first read moves the cursor into the crowded second, so it starts over at its first page.
+	emitted, cursor := accessAuditLogAccountCursor{}.advancePage(records, accessAuditLogsPerPage)
+	assert.Len(t, emitted, accessAuditLogsPerPage)
+	assert.Equal(t, second.Add((accessAuditLogsPerPage-1)*time.Millisecond), cursor.Since)
+	assert.Equal(t, 1, cursor.page())
+
+	// Reading the first page of the second again yields nothing new, so the next page is read.
+	emitted, cursor = cursor.advancePage(records, accessAuditLogsPerPage)
+	assert.Empty(t, emitted)
+	assert.Equal(t, second.Add((accessAuditLogsPerPage-1)*time.Millisecond), cursor.Since)
+	assert.Equal(t, 2, cursor.page())
+
+	// The rest of the second is emitted from the second page, which is read again while short.
+	rest := second.Add(900 * time.Millisecond)
+	emitted, cursor = cursor.advancePage([]cloudflare.AccessAuditLogRecord{{RayID: "ray-rest", CreatedAt: &rest}}, accessAuditLogsPerPage)
+	require.Len(t, emitted, 1)
+	assert.Equal(t, rest, cursor.Since)
+	assert.Equal(t, 2, cursor.page())
+
+	// Events in a later second start over at its first page.
+	later := second.Add(time.Second)
+	emitted, cursor = cursor.advancePage([]cloudflare.AccessAuditLogRecord{{RayID: "ray-later", CreatedAt: &later}}, accessAuditLogsPerPage)
+	require.Len(t, emitted, 1)
+	assert.Equal(t, later, cursor.Since)
+	assert.Equal(t, 1, cursor.page())
+}
+
+// Logins past the first page of a crowded second are read from the following page, not skipped.
+func TestAccessAuditLogListEventsPagesWithinSecond(t *testing.T) {
+	second := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	pages := map[string][]cloudflare.AccessAuditLogRecord{}
+	for i := 0; i < accessAuditLogsPerPage; i++ {
+		createdAt := second.Add(time.Duration(i) * time.Millisecond)
+		pages["1"] = append(pages["1"], cloudflare.AccessAuditLogRecord{RayID: fmt.Sprintf("ray-%d", i), CreatedAt: &createdAt, UserEmail: "jane@example.com"})
+	}
+	last := second.Add(999 * time.Millisecond)
+	pages["2"] = []cloudflare.AccessAuditLogRecord{{RayID: "ray-last", CreatedAt: &last, UserEmail: "jane@example.com"}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/accounts/acct-1/access/logs/access-requests":
+			assert.Equal(t, second.Format(time.RFC3339), r.URL.Query().Get("since"))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "result": pages[r.URL.Query().Get("page")]})
+		case "/accounts/acct-1/access/users":
+			_, _ = w.Write([]byte(`{"success":true,"result":[{"id":"access-user-1","email":"jane@example.com"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := cloudflare.NewWithAPIToken("test-token", cloudflare.BaseURL(server.URL))
+	require.NoError(t, err)
+	feed := accessAuditLogFeedBuilder(client, "acct-1", []string{"acct-1"}, nil)
+	ctx := context.Background()
+
+	events, state, _, err := feed.ListEvents(ctx, timestamppb.New(second), nil)
+	require.NoError(t, err)
+	assert.Len(t, events, accessAuditLogsPerPage)
+	assert.True(t, state.HasMore)
+
+	// Page 1 again yields nothing new, then page 2 yields the last login of the second.
+	var emitted []string
+	for i := 0; i < 2; i++ {
+		events, state, _, err = feed.ListEvents(ctx, nil, &pagination.StreamToken{Cursor: state.Cursor})
+		require.NoError(t, err)
+		for _, event := range events {
+			emitted = append(emitted, event.GetId())
+		}
+	}
+	assert.Equal(t, []string{"ray-last"}, emitted)
+}
+
+func TestAccessAuditLogUsersStale(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	known := []cloudflare.AccessAuditLogRecord{{UserEmail: "Jane@example.com"}}
+	unknown := []cloudflare.AccessAuditLogRecord{{UserEmail: "new@example.com"}}
+	users := accessAuditLogUsers{ids: map[string]string{"jane@example.com": "access-user-1"}, listedAt: now}
+
+	assert.False(t, users.stale(now.Add(time.Second), unknown))
+	assert.False(t, users.stale(now.Add(2*time.Minute), known))
+	assert.True(t, users.stale(now.Add(2*time.Minute), unknown))
+	assert.True(t, users.stale(now.Add(accessAuditLogUsersTTL), known))
+}
+
+func TestAccessAuditLogStreamedAccountIDsCached(t *testing.T) {
+	feed := accessAuditLogFeedBuilder(nil, "", nil, nil)
+	feed.cachedAccountIDs = []string{"acct-1"}
+	feed.accountIDsListedAt = time.Now()
+
+	// A fresh list is reused without listing the accounts again.
+	accountIDs, err := feed.streamedAccountIDs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acct-1"}, accountIDs)
+}
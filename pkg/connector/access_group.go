@@ -0,0 +1,379 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/session"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	accessGroupMemberEntitlement = "member"
+	accessGroupsPerPage          = 50
+	// accessGroupSnapshotKeyPrefix keys the sync's copy of each account's Access groups, which
+	// group and application grants read instead of getting each group.
+	accessGroupSnapshotKeyPrefix = "account_access_groups"
+)
+
+type accessGroupResourceType struct {
+	resourceType *v2.ResourceType
+	client       *cloudflare.API
+	accountId    string
+}
+
+func (o *accessGroupResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+// accessRuleEmail returns the address of an Access email rule, {"email": {"email": "..."}}.
+// Rules are decoded as generic JSON, so any other rule type reports false.
+func accessRuleEmail(rule interface{}) (string, bool) {
+	ruleMap, ok := rule.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	emailRule, ok := ruleMap["email"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	email, ok := emailRule["email"].(string)
+	if !ok || email == "" {
+		return "", false
+	}
+
+	return email, true
+}
+
+// accessGroupEmails returns the addresses named by the group's email include rules.
+func accessGroupEmails(group cloudflare.AccessGroup) []string {
+	var rv []string
+	for _, rule := range group.Include {
+		if email, ok := accessRuleEmail(rule); ok {
+			rv = append(rv, email)
+		}
+	}
+
+	return rv
+}
+
+func accessEmailRule(email string) map[string]interface{} {
+	return map[string]interface{}{
+		"email": map[string]interface{}{"email": email},
+	}
+}
+
+func accessGroupResource(group cloudflare.AccessGroup, resourceID string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"group_id":   group.ID,
+		"group_name": group.Name,
+	}
+
+	displayName := group.Name
+	if displayName == "" {
+		displayName = group.ID
+	}
+
+	opts := []rs.ResourceOption{
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithResourceProfile(profile),
+	}
+	if group.CreatedAt != nil {
+		opts = append(opts, rs.WithResourceCreatedAt(*group.CreatedAt))
+	}
+
+	return rs.NewGroupResource(
+		displayName,
+		resourceTypeAccessGroup,
+		resourceID,
+		nil,
+		opts...,
+	)
+}
+
+func (o *accessGroupResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	page, err := convertPageToken(opts.PageToken.Token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: invalid page token: %w", err)
+	}
+
+	groups, _, err := o.client.ListAccessGroups(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.ListAccessGroupsParams{
+		ResultInfo: cloudflare.ResultInfo{Page: page, PerPage: accessGroupsPerPage},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: failed to list access groups: %w", err)
+	}
+
+	rv := make([]*v2.Resource, 0, len(groups))
+	for _, group := range groups {
+		gr, err := accessGroupResource(group, accountScopedID(o.accountId, accountID, group.ID), parentResourceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		rv = append(rv, gr)
+	}
+
+	nextPage := convertNextPageToken(page, len(groups))
+
+	return rv, &rs.SyncOpResults{NextPageToken: nextPage}, nil
+}
+
+func (o *accessGroupResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
+	rv := []*v2.Entitlement{
+		ent.NewAssignmentEntitlement(
+			resource,
+			accessGroupMemberEntitlement,
+			ent.WithGrantableTo(resourceTypeUser, resourceTypeAccessUser),
+			ent.WithDisplayName(
+				fmt.Sprintf("%s Access Group Member", resource.DisplayName),
+			),
+			ent.WithDescription(
+				fmt.Sprintf("Is named by an email rule of the %s Access group in Cloudflare", resource.DisplayName),
+			),
+		),
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// Grants maps the group's email include rules onto the account's users and its Zero Trust Access
+// users, who need not be members of the account. Other include rules, such as email domains or IdP
+// groups, do not name individual users and are not expanded.
+func (o *accessGroupResourceType) Grants(ctx context.Context, resource *v2.Resource, opts rs.SyncOpAttrs) ([]*v2.Grant, *rs.SyncOpResults, error) {
+	l := ctxzap.Extract(ctx)
+	accountID, groupID := parseAccountScopedID(o.accountId, resource.Id.Resource)
+
+	groups, err := accessGroupsSnapshot(ctx, o.client, opts.Session, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	group, ok := groups[groupID]
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	emails := accessGroupEmails(group)
+	if len(emails) == 0 {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	members, err := accountMembersSnapshot(ctx, o.client, opts.Session, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	membersByEmail := make(map[string]cloudflare.AccountMember, len(members))
+	for _, member := range members {
+		// Pending invitations have no User.ID yet.
+		if member.User.ID == "" {
+			continue
+		}
+		membersByEmail[strings.ToLower(member.User.Email)] = member
+	}
+
+	accessUsers, err := accessUsersSnapshot(ctx, o.client, opts.Session, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	accessUsersByEmail := make(map[string]cloudflare.AccessUser, len(accessUsers))
+	for _, user := range accessUsers {
+		accessUsersByEmail[strings.ToLower(user.Email)] = user
+	}
+
+	var rv []*v2.Grant
+	for _, email := range emails {
+		member, isMember := membersByEmail[strings.ToLower(email)]
+		accessUser, isAccessUser := accessUsersByEmail[strings.ToLower(email)]
+		if !isMember && !isAccessUser {
+			l.Debug(
+				"baton-cloudflare: access group email rule does not match an account member or access user",
+				zap.String("group_id", groupID),
+				zap.String("email", email),
+			)
+			continue
+		}
+
+		if isMember {
+			ur, err := userResource(member, accountScopedID(o.accountId, accountID, member.User.ID), accountResourceID(accountID))
+			if err != nil {
+				return nil, nil, wrapError(err, "failed to create user resource")
+			}
+			rv = append(rv, grant.NewGrant(resource, accessGroupMemberEntitlement, ur.Id))
+		}
+		if isAccessUser {
+			rv = append(rv, grant.NewGrant(resource, accessGroupMemberEntitlement, accessUserResourceID(o.accountId, accountID, accessUser)))
+		}
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// Grant adds an email include rule for the principal to the Access group.
+func (o *accessGroupResourceType) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	email, err := principalEmail(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	accountID, groupID := parseAccountScopedID(o.accountId, entitlement.Resource.Id.Resource)
+	group, err := o.client.GetAccessGroup(ctx, cloudflare.AccountIdentifier(accountID), groupID)
+	if err != nil {
+		return nil, fmt.Errorf("baton-cloudflare: failed to get access group: %w", err)
+	}
+
+	for _, existing := range accessGroupEmails(group) {
+		if strings.EqualFold(existing, email) {
+			l.Warn(
+				"baton-cloudflare: access group already has an email rule for the principal",
+				zap.String("principal_id", principal.Id.String()),
+				zap.String("group_id", groupID),
+			)
+			return annotations.New(&v2.GrantAlreadyExists{}), nil
+		}
+	}
+
+	include := append(group.Include, accessEmailRule(email))
+	if err := o.updateAccessGroupInclude(ctx, accountID, group, include); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// Revoke removes every email include rule naming the principal from the Access group.
+func (o *accessGroupResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	principal := grant.Principal
+	email, err := principalEmail(principal)
+	if err != nil {
+		return nil, err
+	}
+
+	accountID, groupID := parseAccountScopedID(o.accountId, grant.Entitlement.Resource.Id.Resource)
+	group, err := o.client.GetAccessGroup(ctx, cloudflare.AccountIdentifier(accountID), groupID)
+	if err != nil {
+		return nil, fmt.Errorf("baton-cloudflare: failed to get access group: %w", err)
+	}
+
+	include := make([]interface{}, 0, len(group.Include))
+	for _, rule := range group.Include {
+		if existing, ok := accessRuleEmail(rule); ok && strings.EqualFold(existing, email) {
+			continue
+		}
+		include = append(include, rule)
+	}
+	if len(include) == len(group.Include) {
+		l.Warn(
+			"baton-cloudflare: access group has no email rule for the principal",
+			zap.String("principal_id", principal.Id.String()),
+			zap.String("group_id", groupID),
+		)
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+	// Cloudflare rejects an Access group without include rules.
+	if len(include) == 0 {
+		return nil, fmt.Errorf("baton-cloudflare: cannot remove the last include rule of access group %s", groupID)
+	}
+
+	if err := o.updateAccessGroupInclude(ctx, accountID, group, include); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// updateAccessGroupInclude replaces the group's include rules, keeping its other rules as they are.
+func (o *accessGroupResourceType) updateAccessGroupInclude(ctx context.Context, accountID string, group cloudflare.AccessGroup, include []interface{}) error {
+	_, err := o.client.UpdateAccessGroup(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.UpdateAccessGroupParams{
+		ID:      group.ID,
+		Name:    group.Name,
+		Include: include,
+		Exclude: group.Exclude,
+		Require: group.Require,
+	})
+	if err != nil {
+		return fmt.Errorf("baton-cloudflare: failed to update access group: %w", err)
+	}
+
+	return nil
+}
+
+// accessGroupsSnapshot returns every Access group of the account, keyed by ID. During a sync the
+// groups are listed once and kept in the session store; without one they are listed directly.
+func accessGroupsSnapshot(ctx context.Context, client *cloudflare.API, ss sessions.SessionStore, accountID string) (map[string]cloudflare.AccessGroup, error) {
+	key := fmt.Sprintf("%s:%s", accessGroupSnapshotKeyPrefix, accountID)
+	if ss != nil {
+		groups, found, err := session.GetJSON[map[string]cloudflare.AccessGroup](ctx, ss, key)
+		if err != nil {
+			return nil, fmt.Errorf("baton-cloudflare: failed to read access group snapshot: %w", err)
+		}
+		if found {
+			return groups, nil
+		}
+	}
+
+	groups := make(map[string]cloudflare.AccessGroup)
+	for page := 1; ; page++ {
+		listed, _, err := client.ListAccessGroups(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.ListAccessGroupsParams{
+			ResultInfo: cloudflare.ResultInfo{Page: page, PerPage: accessGroupsPerPage},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("baton-cloudflare: failed to list access groups: %w", err)
+		}
+		for _, group := range listed {
+			groups[group.ID] = group
+		}
+		if len(listed) < accessGroupsPerPage {
+			break
+		}
+	}
+
+	if ss != nil {
+		if err := session.SetJSON(ctx, ss, key, groups); err != nil {
+			return nil, fmt.Errorf("baton-cloudflare: failed to store access group snapshot: %w", err)
+		}
+	}
+
+	return groups, nil
+}
+
+// principalEmail returns the email address of a user or Access user principal, read from its
+// profile or user trait.
+func principalEmail(principal *v2.Resource) (string, error) {
+	if email, ok := rs.GetProfileStringValue(rs.GetProfile(principal), "email"); ok && email != "" {
+		return email, nil
+	}
+
+	userTrait, err := rs.GetUserTrait(principal)
+	if err == nil {
+		for _, email := range userTrait.GetEmails() {
+			if email.GetAddress() != "" {
+				return email.GetAddress(), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("baton-cloudflare: principal %s has no email address", principal.Id.Resource)
+}
+
+func accessGroupBuilder(client *cloudflare.API, accountId string) *accessGroupResourceType {
+	return &accessGroupResourceType{
+		resourceType: resourceTypeAccessGroup,
+		client:       client,
+		accountId:    accountId,
+	}
+}
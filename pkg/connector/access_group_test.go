@@ -0,0 +1,110 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/conductorone/baton-sdk/pkg/session"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessGroupEmails(t *testing.T) {
+	body := `{
+		"id": "group-1",
+		"name": "Engineering",
+		"include": [
+			{"email": {"email": "jane@example.com"}},
+			{"email_domain": {"domain": "example.com"}},
+			{"group": {"id": "group-2"}},
+			{"email": {"email": "john@example.com"}}
+		]
+	}`
+
+	var group cloudflare.AccessGroup
+	require.NoError(t, json.Unmarshal([]byte(body), &group))
+	assert.Equal(t, []string{"jane@example.com", "john@example.com"}, accessGroupEmails(group))
+
+	// A rule added on Grant round-trips as an email rule.
+	email, ok := accessRuleEmail(accessEmailRule("new@example.com"))
+	assert.True(t, ok)
+	assert.Equal(t, "new@example.com", email)
+}
+
+func TestAccessGroupResource(t *testing.T) {
+	group := cloudflare.AccessGroup{ID: "group-1", Name: "Engineering"}
+
+	resource, err := accessGroupResource(group, accountScopedID("acct-1", "acct-2", group.ID), accountResourceID("acct-2"))
+	require.NoError(t, err)
+	assert.Equal(t, "acct-2/group-1", resource.GetId().GetResource())
+	assert.Equal(t, resourceTypeAccessGroup.GetId(), resource.GetId().GetResourceType())
+	assert.Equal(t, "Engineering", resource.GetDisplayName())
+}
+
+func TestPrincipalEmail(t *testing.T) {
+	principal, err := userResource(cloudflare.AccountMember{
+		ID:   "member-1",
+		User: cloudflare.AccountMemberUserDetails{ID: "user-1", Email: "jane@example.com"},
+	}, "user-1", nil)
+	require.NoError(t, err)
+
+	email, err := principalEmail(principal)
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", email)
+}
+
+func TestAccessGroupsSnapshotFromSession(t *testing.T) {
+	ctx := context.Background()
+	ss := newMapSessionStore()
+	groups := map[string]cloudflare.AccessGroup{
+		"group-1": {ID: "group-1", Name: "Engineering", Include: []interface{}{accessEmailRule("jane@example.com")}},
+	}
+	require.NoError(t, session.SetJSON(ctx, ss, accessGroupSnapshotKeyPrefix+":acct-1", groups))
+
+	// A stored snapshot is read without calling Cloudflare.
+	got, err := accessGroupsSnapshot(ctx, nil, ss, "acct-1")
+	require.NoError(t, err)
+	require.Contains(t, got, "group-1")
+	assert.Equal(t, []string{"jane@example.com"}, accessGroupEmails(got["group-1"]))
+}
+
+func TestAccessGroupResourceProfile(t *testing.T) {
+	resource, err := accessGroupResource(cloudflare.AccessGroup{ID: "group-1", Name: "Engineering"}, "group-1", accountResourceID("acct-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "Engineering", resource.GetProfile().GetFields()["group_name"].GetStringValue())
+}
+
+// Email rules naming Zero Trust users who are not account members are granted to the Access user.
+func TestAccessGroupGrantsAccessUsers(t *testing.T) {
+	ctx := context.Background()
+	ss := newMapSessionStore()
+	groups := map[string]cloudflare.AccessGroup{
+		"group-1": {ID: "group-1", Name: "Engineering", Include: []interface{}{
+			accessEmailRule("jane@example.com"),
+			accessEmailRule("Contractor@partner.com"),
+			accessEmailRule("nobody@example.com"),
+		}},
+	}
+	require.NoError(t, session.SetJSON(ctx, ss, accessGroupSnapshotKeyPrefix+":acct-1", groups))
+	require.NoError(t, setMemberSnapshot(ctx, ss, "acct-1", [][]cloudflare.AccountMember{{
+		{ID: "member-1", User: cloudflare.AccountMemberUserDetails{ID: "user-1", Email: "jane@example.com"}},
+	}}))
+	require.NoError(t, setAccessUserSnapshot(ctx, ss, "acct-1", [][]cloudflare.AccessUser{{
+		{ID: "access-user-1", Email: "jane@example.com"},
+		{ID: "access-user-2", Email: "contractor@partner.com"},
+	}}))
+
+	resource, err := accessGroupResource(groups["group-1"], "group-1", accountResourceID("acct-1"))
+	require.NoError(t, err)
+	grants, _, err := accessGroupBuilder(nil, "acct-1").Grants(ctx, resource, rs.SyncOpAttrs{Session: ss})
+	require.NoError(t, err)
+
+	var principals []string
+	for _, g := range grants {
+		principals = append(principals, g.GetPrincipal().GetId().GetResourceType()+":"+g.GetPrincipal().GetId().GetResource())
+	}
+	assert.ElementsMatch(t, []string{"user:user-1", "access_user:access-user-1", "access_user:access-user-2"}, principals)
+}
@@ -0,0 +1,98 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const accessIdentityProvidersPerPage = 50
+
+type accessIdentityProviderResourceType struct {
+	resourceType *v2.ResourceType
+	client       *cloudflare.API
+	accountId    string
+}
+
+func (o *accessIdentityProviderResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+// accessIdentityProviderResource builds a login method configured in Access, such as Okta, Azure AD,
+// GitHub or one-time PIN. The provider's configuration holds client secrets, so only its type and
+// SCIM settings are copied into the profile.
+func accessIdentityProviderResource(idp cloudflare.AccessIdentityProvider, resourceID string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"idp_id":                        idp.ID,
+		"idp_name":                      idp.Name,
+		"idp_type":                      idp.Type,
+		"scim_enabled":                  idp.ScimConfig.Enabled,
+		"scim_user_deprovision":         idp.ScimConfig.UserDeprovision,
+		"scim_seat_deprovision":         idp.ScimConfig.SeatDeprovision,
+		"scim_group_member_deprovision": idp.ScimConfig.GroupMemberDeprovision,
+	}
+
+	displayName := idp.Name
+	if displayName == "" {
+		displayName = idp.Type
+	}
+
+	return rs.NewResource(
+		displayName,
+		resourceTypeAccessIdentityProvider,
+		resourceID,
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithResourceProfile(profile),
+	)
+}
+
+func (o *accessIdentityProviderResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	page, err := convertPageToken(opts.PageToken.Token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: invalid page token: %w", err)
+	}
+
+	idps, _, err := o.client.ListAccessIdentityProviders(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.ListAccessIdentityProvidersParams{
+		ResultInfo: cloudflare.ResultInfo{Page: page, PerPage: accessIdentityProvidersPerPage},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: failed to list access identity providers: %w", err)
+	}
+
+	rv := make([]*v2.Resource, 0, len(idps))
+	for _, idp := range idps {
+		ir, err := accessIdentityProviderResource(idp, accountScopedID(o.accountId, accountID, idp.ID), parentResourceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		rv = append(rv, ir)
+	}
+
+	nextPage := convertNextPageToken(page, len(idps))
+
+	return rv, &rs.SyncOpResults{NextPageToken: nextPage}, nil
+}
+
+func (o *accessIdentityProviderResourceType) Entitlements(_ context.Context, _ *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
+	return nil, &rs.SyncOpResults{}, nil
+}
+
+func (o *accessIdentityProviderResourceType) Grants(_ context.Context, _ *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Grant, *rs.SyncOpResults, error) {
+	return nil, &rs.SyncOpResults{}, nil
+}
+
+func accessIdentityProviderBuilder(client *cloudflare.API, accountId string) *accessIdentityProviderResourceType {
+	return &accessIdentityProviderResourceType{
+		resourceType: resourceTypeAccessIdentityProvider,
+		client:       client,
+		accountId:    accountId,
+	}
+}
@@ -0,0 +1,33 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessIdentityProviderResource(t *testing.T) {
+	idp := cloudflare.AccessIdentityProvider{
+		ID:   "idp-1",
+		Name: "Okta",
+		Type: "okta",
+		Config: cloudflare.AccessIdentityProviderConfiguration{
+			ClientID:     "client-id",
+			ClientSecret: "client-secret",
+		},
+		ScimConfig: cloudflare.AccessIdentityProviderScimConfiguration{Enabled: true, UserDeprovision: true},
+	}
+
+	resource, err := accessIdentityProviderResource(idp, idp.ID, accountResourceID("acct-1"))
+	require.NoError(t, err)
+	assert.Equal(t, resourceTypeAccessIdentityProvider.GetId(), resource.GetId().GetResourceType())
+	assert.Equal(t, "Okta", resource.GetDisplayName())
+
+	profile := resource.GetProfile().AsMap()
+	assert.Equal(t, "okta", profile["idp_type"])
+	assert.Equal(t, true, profile["scim_enabled"])
+	assert.Equal(t, true, profile["scim_user_deprovision"])
+	assert.NotContains(t, profile, "client_secret")
+}
@@ -0,0 +1,277 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/session"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
+)
+
+const (
+	accessPolicyIncludedEntitlement = "included"
+	// accessPolicyIDSeparator joins the application ID onto the policy ID; reusable policies are
+	// attached to several applications, so the policy ID alone does not identify a resource.
+	accessPolicyIDSeparator = ":"
+
+	accessPolicySnapshotKeyPrefix = "access_policies"
+)
+
+func accessPolicySnapshotKey(accountID, appID, policyID string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", accessPolicySnapshotKeyPrefix, accountID, appID, policyID)
+}
+
+type accessPolicyResourceType struct {
+	resourceType *v2.ResourceType
+	client       *cloudflare.API
+	accountId    string
+}
+
+func (o *accessPolicyResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+// describeAccessRule renders an Access rule as "type: value", such as "email: jane@example.com",
+// "email_domain: example.com" or "everyone". Rules whose value is not a single string keep the
+// value as JSON.
+func describeAccessRule(rule interface{}) string {
+	ruleMap, ok := rule.(map[string]interface{})
+	if !ok || len(ruleMap) == 0 {
+		b, _ := json.Marshal(rule)
+		return string(b)
+	}
+
+	ruleTypes := make([]string, 0, len(ruleMap))
+	for ruleType := range ruleMap {
+		ruleTypes = append(ruleTypes, ruleType)
+	}
+	sort.Strings(ruleTypes)
+
+	descriptions := make([]string, 0, len(ruleTypes))
+	for _, ruleType := range ruleTypes {
+		descriptions = append(descriptions, describeAccessRuleValue(ruleType, ruleMap[ruleType]))
+	}
+
+	return strings.Join(descriptions, ", ")
+}
+
+func describeAccessRuleValue(ruleType string, value interface{}) string {
+	valueMap, ok := value.(map[string]interface{})
+	if ok && len(valueMap) == 0 {
+		return ruleType
+	}
+	if ok && len(valueMap) == 1 {
+		for _, v := range valueMap {
+			if s, ok := v.(string); ok {
+				return fmt.Sprintf("%s: %s", ruleType, s)
+			}
+		}
+	}
+
+	b, _ := json.Marshal(value)
+	return fmt.Sprintf("%s: %s", ruleType, string(b))
+}
+
+func describeAccessRules(rules []interface{}) []interface{} {
+	rv := make([]interface{}, 0, len(rules))
+	for _, rule := range rules {
+		rv = append(rv, describeAccessRule(rule))
+	}
+
+	return rv
+}
+
+// describeApprovalGroups renders each approval group as "N of approvers", where approvers are the
+// group's email addresses or its email list.
+func describeApprovalGroups(groups []cloudflare.AccessApprovalGroup) []interface{} {
+	rv := make([]interface{}, 0, len(groups))
+	for _, group := range groups {
+		approvers := strings.Join(group.EmailAddresses, ", ")
+		if group.EmailListUuid != "" {
+			approvers = fmt.Sprintf("email list %s", group.EmailListUuid)
+		}
+		rv = append(rv, fmt.Sprintf("%d of %s", group.ApprovalsNeeded, approvers))
+	}
+
+	return rv
+}
+
+func accessPolicyResourceID(appID, policyID string) string {
+	return appID + accessPolicyIDSeparator + policyID
+}
+
+func parseAccessPolicyResourceID(resourceID string) (string, string, error) {
+	appID, policyID, ok := strings.Cut(resourceID, accessPolicyIDSeparator)
+	if !ok || appID == "" || policyID == "" {
+		return "", "", fmt.Errorf("baton-cloudflare: invalid access policy resource id %q", resourceID)
+	}
+
+	return appID, policyID, nil
+}
+
+func accessPolicyResource(policy cloudflare.AccessPolicy, appID string, resourceID string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"policy_id":                      policy.ID,
+		"policy_name":                    policy.Name,
+		"app_id":                         appID,
+		"decision":                       policy.Decision,
+		"precedence":                     policy.Precedence,
+		"reusable":                       policy.Reusable != nil && *policy.Reusable,
+		"include":                        describeAccessRules(policy.Include),
+		"exclude":                        describeAccessRules(policy.Exclude),
+		"require":                        describeAccessRules(policy.Require),
+		"approval_required":              policy.ApprovalRequired != nil && *policy.ApprovalRequired,
+		"approval_groups":                describeApprovalGroups(policy.ApprovalGroups),
+		"purpose_justification_required": policy.PurposeJustificationRequired != nil && *policy.PurposeJustificationRequired,
+		"isolation_required":             policy.IsolationRequired != nil && *policy.IsolationRequired,
+	}
+	if policy.SessionDuration != nil {
+		profile["session_duration"] = *policy.SessionDuration
+	}
+
+	displayName := policy.Name
+	if displayName == "" {
+		displayName = policy.ID
+	}
+
+	opts := []rs.ResourceOption{
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithResourceProfile(profile),
+	}
+	if policy.CreatedAt != nil {
+		opts = append(opts, rs.WithResourceCreatedAt(*policy.CreatedAt))
+	}
+
+	return rs.NewResource(
+		displayName,
+		resourceTypeAccessPolicy,
+		resourceID,
+		opts...,
+	)
+}
+
+// List returns the Access policies attached to the parent application.
+func (o *accessPolicyResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	if parentResourceID == nil || parentResourceID.ResourceType != resourceTypeAccessApplication.Id {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	accountID, appID := parseAccountScopedID(o.accountId, parentResourceID.Resource)
+	policies, _, err := o.client.ListAccessPolicies(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.ListAccessPoliciesParams{
+		ApplicationID: appID,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: failed to list access policies: %w", err)
+	}
+
+	rv := make([]*v2.Resource, 0, len(policies))
+	snapshot := make(map[string]cloudflare.AccessPolicy, len(policies))
+	for _, policy := range policies {
+		resourceID := accountScopedID(o.accountId, accountID, accessPolicyResourceID(appID, policy.ID))
+		pr, err := accessPolicyResource(policy, appID, resourceID, parentResourceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		rv = append(rv, pr)
+		snapshot[accessPolicySnapshotKey(accountID, appID, policy.ID)] = policy
+	}
+
+	// Grants evaluate the policy's rules; keeping the listed policies in the session saves a
+	// policy lookup per policy.
+	if opts.Session != nil && len(snapshot) > 0 {
+		if err := session.SetManyJSON(ctx, opts.Session, snapshot); err != nil {
+			return nil, nil, fmt.Errorf("baton-cloudflare: failed to store access policy snapshot: %w", err)
+		}
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// accessPolicySnapshot returns the policy as stored by the policy List during the sync, or looks it
+// up when it is not stored.
+func accessPolicySnapshot(ctx context.Context, client *cloudflare.API, ss sessions.SessionStore, accountID, appID, policyID string) (cloudflare.AccessPolicy, error) {
+	if ss != nil {
+		policy, found, err := session.GetJSON[cloudflare.AccessPolicy](ctx, ss, accessPolicySnapshotKey(accountID, appID, policyID))
+		if err != nil {
+			return cloudflare.AccessPolicy{}, fmt.Errorf("baton-cloudflare: failed to read access policy snapshot: %w", err)
+		}
+		if found {
+			return policy, nil
+		}
+	}
+
+	policy, err := client.GetAccessPolicy(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.GetAccessPolicyParams{
+		ApplicationID: appID,
+		PolicyID:      policyID,
+	})
+	if err != nil {
+		return cloudflare.AccessPolicy{}, fmt.Errorf("baton-cloudflare: failed to get access policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// Entitlements returns the read-only included entitlement, granted to everyone the policy's
+// include rules name regardless of its decision, so bypass and "everyone" rules are reviewable.
+func (o *accessPolicyResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
+	rv := []*v2.Entitlement{
+		ent.NewPermissionEntitlement(
+			resource,
+			accessPolicyIncludedEntitlement,
+			ent.WithGrantableTo(resourceTypeUser, resourceTypeAccessUser, resourceTypeAccessGroup),
+			ent.WithDisplayName(
+				fmt.Sprintf("%s Included", resource.DisplayName),
+			),
+			ent.WithDescription(
+				fmt.Sprintf("Is named by the include rules of the %s Access policy", resource.DisplayName),
+			),
+			ent.WithAnnotation(&v2.EntitlementImmutable{}),
+		),
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// Grants grants the included entitlement to the account members and Access users the policy's
+// include rules match, less those its exclude rules keep out, and to the Access groups it includes
+// when it has no exclude rules; group grants expand to the groups' members. Require rules are not
+// evaluated. The policy is read as the policy List stored it during the sync.
+func (o *accessPolicyResourceType) Grants(ctx context.Context, resource *v2.Resource, opts rs.SyncOpAttrs) ([]*v2.Grant, *rs.SyncOpResults, error) {
+	accountID, id := parseAccountScopedID(o.accountId, resource.Id.Resource)
+	appID, policyID, err := parseAccessPolicyResourceID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	policy, err := accessPolicySnapshot(ctx, o.client, opts.Session, accountID, appID, policyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Included is about the include rules whatever the decision, so require rules do not narrow it.
+	policy.Require = nil
+	audience := &accessPolicyAudience{}
+	audience.add(policy)
+
+	rv, err := accessAudienceGrants(ctx, o.client, opts.Session, o.accountId, accountID, resource, accessPolicyIncludedEntitlement, audience)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+func accessPolicyBuilder(client *cloudflare.API, accountId string) *accessPolicyResourceType {
+	return &accessPolicyResourceType{
+		resourceType: resourceTypeAccessPolicy,
+		client:       client,
+		accountId:    accountId,
+	}
+}
@@ -0,0 +1,89 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/conductorone/baton-sdk/pkg/session"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessPolicyResource(t *testing.T) {
+	body := `{
+		"id": "policy-1",
+		"name": "Engineers",
+		"decision": "bypass",
+		"precedence": 1,
+		"session_duration": "24h",
+		"approval_required": true,
+		"approval_groups": [{"email_addresses": ["lead@example.com"], "approvals_needed": 1}],
+		"include": [
+			{"everyone": {}},
+			{"email_domain": {"domain": "example.com"}},
+			{"ip": {"ip": "10.0.0.0/8"}}
+		],
+		"exclude": [{"email": {"email": "contractor@example.com"}}],
+		"require": [{"auth_method": {"auth_method": "mfa"}}]
+	}`
+
+	var policy cloudflare.AccessPolicy
+	require.NoError(t, json.Unmarshal([]byte(body), &policy))
+
+	resourceID := accountScopedID("acct-1", "acct-2", accessPolicyResourceID("app-1", policy.ID))
+	resource, err := accessPolicyResource(policy, "app-1", resourceID, accountResourceID("acct-2"))
+	require.NoError(t, err)
+	assert.Equal(t, "acct-2/app-1:policy-1", resource.GetId().GetResource())
+	assert.Equal(t, "Engineers", resource.GetDisplayName())
+
+	profile := resource.GetProfile().AsMap()
+	assert.Equal(t, "bypass", profile["decision"])
+	assert.Equal(t, "24h", profile["session_duration"])
+	assert.Equal(t, true, profile["approval_required"])
+	assert.Equal(t, []interface{}{"1 of lead@example.com"}, profile["approval_groups"])
+	assert.Equal(t, []interface{}{"everyone", "email_domain: example.com", "ip: 10.0.0.0/8"}, profile["include"])
+	assert.Equal(t, []interface{}{"email: contractor@example.com"}, profile["exclude"])
+	assert.Equal(t, []interface{}{"auth_method: mfa"}, profile["require"])
+
+	accountID, id := parseAccountScopedID("acct-1", resource.GetId().GetResource())
+	assert.Equal(t, "acct-2", accountID)
+	appID, policyID, err := parseAccessPolicyResourceID(id)
+	require.NoError(t, err)
+	assert.Equal(t, "app-1", appID)
+	assert.Equal(t, "policy-1", policyID)
+
+	_, _, err = parseAccessPolicyResourceID("policy-1")
+	assert.Error(t, err)
+}
+
+// Grants read the policy stored by List, so no policy is fetched, and include Access users.
+func TestAccessPolicyGrantsFromSession(t *testing.T) {
+	ctx := context.Background()
+	ss := newMapSessionStore()
+	policy := cloudflare.AccessPolicy{
+		ID:       "policy-1",
+		Decision: "bypass",
+		Include:  []interface{}{map[string]interface{}{"email_domain": map[string]interface{}{"domain": "partner.com"}}},
+		Exclude:  []interface{}{accessEmailRule("former@partner.com")},
+	}
+	require.NoError(t, session.SetJSON(ctx, ss, accessPolicySnapshotKey("acct-1", "app-1", "policy-1"), policy))
+	require.NoError(t, setMemberSnapshot(ctx, ss, "acct-1", [][]cloudflare.AccountMember{{
+		{ID: "member-1", User: cloudflare.AccountMemberUserDetails{ID: "user-1", Email: "jane@example.com"}},
+	}}))
+	require.NoError(t, setAccessUserSnapshot(ctx, ss, "acct-1", [][]cloudflare.AccessUser{{
+		{ID: "access-user-1", Email: "sam@partner.com"},
+		{ID: "access-user-2", Email: "former@partner.com"},
+	}}))
+
+	resource, err := accessPolicyResource(policy, "app-1", accessPolicyResourceID("app-1", "policy-1"), nil)
+	require.NoError(t, err)
+	grants, _, err := accessPolicyBuilder(nil, "acct-1").Grants(ctx, resource, rs.SyncOpAttrs{Session: ss})
+	require.NoError(t, err)
+
+	require.Len(t, grants, 1)
+	assert.Equal(t, resourceTypeAccessUser.Id, grants[0].GetPrincipal().GetId().GetResourceType())
+	assert.Equal(t, "access-user-1", grants[0].GetPrincipal().GetId().GetResource())
+}
@@ -0,0 +1,218 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	// accessSeatEntitlement and gatewaySeatEntitlement are the Zero Trust seats an Access user
+	// holds. Cloudflare assigns them when the user logs in or enrolls a device; they can only be
+	// released.
+	accessSeatEntitlement  = "access_seat"
+	gatewaySeatEntitlement = "gateway_seat"
+)
+
+// isSeatEntitlement reports whether the entitlement is one of the account's seat entitlements.
+func isSeatEntitlement(entitlement *v2.Entitlement) bool {
+	return strings.HasSuffix(entitlement.Id, ":"+accessSeatEntitlement) || strings.HasSuffix(entitlement.Id, ":"+gatewaySeatEntitlement)
+}
+
+func accessSeatEntitlements(resource *v2.Resource) []*v2.Entitlement {
+	return []*v2.Entitlement{
+		ent.NewPermissionEntitlement(
+			resource,
+			accessSeatEntitlement,
+			ent.WithGrantableTo(resourceTypeAccessUser),
+			ent.WithDisplayName(fmt.Sprintf("%s Access Seat", resource.DisplayName)),
+			ent.WithDescription(fmt.Sprintf("Holds a Zero Trust Access seat in the %s Cloudflare account", resource.DisplayName)),
+		),
+		ent.NewPermissionEntitlement(
+			resource,
+			gatewaySeatEntitlement,
+			ent.WithGrantableTo(resourceTypeAccessUser),
+			ent.WithDisplayName(fmt.Sprintf("%s Gateway Seat", resource.DisplayName)),
+			ent.WithDescription(fmt.Sprintf("Holds a Zero Trust Gateway seat in the %s Cloudflare account", resource.DisplayName)),
+		),
+	}
+}
+
+// accessSeatGrants grants the seat entitlements to the Access users holding each seat. Accounts
+// without Zero Trust, or credentials without access to its users, have no seats to report.
+func (o *accountResourceType) accessSeatGrants(ctx context.Context, resource *v2.Resource) ([]*v2.Grant, error) {
+	l := ctxzap.Extract(ctx)
+	accountID := resource.Id.Resource
+
+	users, _, err := o.client.ListAccessUsers(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.AccessUserParams{})
+	if err != nil {
+		var authzErr *cloudflare.AuthorizationError
+		if errors.As(err, &authzErr) {
+			l.Warn(
+				"baton-cloudflare: not authorized to list access users, skipping seat grants",
+				zap.String("account_id", accountID),
+				zap.Error(err),
+			)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("baton-cloudflare: failed to list access users: %w", err)
+	}
+
+	var rv []*v2.Grant
+	for _, user := range users {
+		seats := accessUserSeatsFromUser(user)
+		if !seats.accessSeat && !seats.gatewaySeat {
+			continue
+		}
+
+		ur, err := accessUserResource(user, nil, accountScopedID(o.accountId, accountID, user.ID), resource.Id)
+		if err != nil {
+			return nil, wrapError(err, "failed to create access user resource")
+		}
+		if seats.accessSeat {
+			rv = append(rv, grant.NewGrant(resource, accessSeatEntitlement, ur.Id))
+		}
+		if seats.gatewaySeat {
+			rv = append(rv, grant.NewGrant(resource, gatewaySeatEntitlement, ur.Id))
+		}
+	}
+
+	return rv, nil
+}
+
+// Grant is not supported: Cloudflare assigns seats itself when a user logs in or enrolls a device.
+func (o *accountResourceType) Grant(_ context.Context, _ *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	if isSeatEntitlement(entitlement) {
+		return nil, fmt.Errorf("baton-cloudflare: seats are assigned by Cloudflare when a user logs in and cannot be granted")
+	}
+
+	return nil, fmt.Errorf("baton-cloudflare: account entitlements are read-only")
+}
+
+// Revoke releases the Access user's seat, leaving its other seat as it is.
+func (o *accountResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	entitlement := grant.Entitlement
+	if !isSeatEntitlement(entitlement) {
+		return nil, fmt.Errorf("baton-cloudflare: account entitlements are read-only")
+	}
+
+	principal := grant.Principal
+	if principal.Id.ResourceType != resourceTypeAccessUser.Id {
+		return nil, fmt.Errorf("baton-cloudflare: only access users can have seats revoked")
+	}
+
+	accountID := entitlement.Resource.Id.Resource
+	seats, ok := accessUserSeatsFromProfile(principal)
+	if !ok {
+		_, accessUserID := parseAccountScopedID(o.accountId, principal.Id.Resource)
+		user, found, err := findAccessUser(ctx, o.client, accountID, accessUserID)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			seats = accessUserSeatsFromUser(user)
+		}
+	}
+
+	params, held := seats.release(entitlement.Id)
+	if !held {
+		l.Warn(
+			"baton-cloudflare: access user does not hold the seat",
+			zap.String("principal_id", principal.Id.String()),
+			zap.String("entitlement_id", entitlement.Id),
+		)
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	_, err := o.client.UpdateAccessUserSeat(ctx, cloudflare.AccountIdentifier(accountID), params)
+	if err != nil {
+		return nil, fmt.Errorf("baton-cloudflare: failed to release seat: %w", err)
+	}
+
+	return nil, nil
+}
+
+// accessUserSeats is the seat assignment of an Access user.
+type accessUserSeats struct {
+	seatUID     string
+	accessSeat  bool
+	gatewaySeat bool
+}
+
+func accessUserSeatsFromUser(user cloudflare.AccessUser) accessUserSeats {
+	return accessUserSeats{
+		seatUID:     user.SeatUID,
+		accessSeat:  user.AccessSeat != nil && *user.AccessSeat,
+		gatewaySeat: user.GatewaySeat != nil && *user.GatewaySeat,
+	}
+}
+
+// accessUserSeatsFromProfile reads the seats recorded in the access user's profile, reporting
+// false when the profile has no seat UID to update.
+func accessUserSeatsFromProfile(principal *v2.Resource) (accessUserSeats, bool) {
+	profile := rs.GetProfile(principal)
+	if profile == nil {
+		return accessUserSeats{}, false
+	}
+
+	seatUID, ok := rs.GetProfileStringValue(profile, "seat_uid")
+	if !ok || seatUID == "" {
+		return accessUserSeats{}, false
+	}
+
+	return accessUserSeats{
+		seatUID:     seatUID,
+		accessSeat:  profile.GetFields()["access_seat"].GetBoolValue(),
+		gatewaySeat: profile.GetFields()["gateway_seat"].GetBoolValue(),
+	}, true
+}
+
+// release returns the update that releases the seat behind the entitlement, and whether the user
+// holds that seat at all.
+func (s accessUserSeats) release(entitlementID string) (cloudflare.UpdateAccessUserSeatParams, bool) {
+	accessSeat, gatewaySeat := s.accessSeat, s.gatewaySeat
+	if strings.HasSuffix(entitlementID, ":"+accessSeatEntitlement) {
+		if !accessSeat {
+			return cloudflare.UpdateAccessUserSeatParams{}, false
+		}
+		accessSeat = false
+	} else {
+		if !gatewaySeat {
+			return cloudflare.UpdateAccessUserSeatParams{}, false
+		}
+		gatewaySeat = false
+	}
+
+	return cloudflare.UpdateAccessUserSeatParams{
+		SeatUID:     s.seatUID,
+		AccessSeat:  &accessSeat,
+		GatewaySeat: &gatewaySeat,
+	}, true
+}
+
+// findAccessUser looks up an Access user by ID; the users endpoint has no single-user lookup.
+func findAccessUser(ctx context.Context, client *cloudflare.API, accountID, accessUserID string) (cloudflare.AccessUser, bool, error) {
+	users, _, err := client.ListAccessUsers(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.AccessUserParams{})
+	if err != nil {
+		return cloudflare.AccessUser{}, false, fmt.Errorf("baton-cloudflare: failed to list access users: %w", err)
+	}
+
+	for _, user := range users {
+		if user.ID == accessUserID {
+			return user, true, nil
+		}
+	}
+
+	return cloudflare.AccessUser{}, false, nil
+}
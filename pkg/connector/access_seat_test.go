@@ -0,0 +1,76 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seatTestFixtures(t *testing.T, accessSeat, gatewaySeat bool) (*v2.Resource, *v2.Resource) {
+	t.Helper()
+
+	account, err := accountResource(cloudflare.Account{ID: "acct-1", Name: "Production"})
+	require.NoError(t, err)
+
+	user, err := accessUserResource(cloudflare.AccessUser{
+		ID:          "access-user-1",
+		Email:       "jane@example.com",
+		SeatUID:     "seat-1",
+		AccessSeat:  &accessSeat,
+		GatewaySeat: &gatewaySeat,
+	}, nil, "access-user-1", account.Id)
+	require.NoError(t, err)
+
+	return account, user
+}
+
+func TestAccessUserSeatsFromProfile(t *testing.T) {
+	_, user := seatTestFixtures(t, true, false)
+
+	seats, ok := accessUserSeatsFromProfile(user)
+	require.True(t, ok)
+	assert.Equal(t, accessUserSeats{seatUID: "seat-1", accessSeat: true}, seats)
+
+	_, ok = accessUserSeatsFromProfile(&v2.Resource{Id: user.Id})
+	assert.False(t, ok)
+}
+
+func TestAccessUserSeatsRelease(t *testing.T) {
+	seats := accessUserSeats{seatUID: "seat-1", accessSeat: true, gatewaySeat: true}
+
+	params, held := seats.release("account:acct-1:" + accessSeatEntitlement)
+	require.True(t, held)
+	assert.Equal(t, "seat-1", params.SeatUID)
+	assert.False(t, *params.AccessSeat)
+	assert.True(t, *params.GatewaySeat)
+
+	params, held = seats.release("account:acct-1:" + gatewaySeatEntitlement)
+	require.True(t, held)
+	assert.True(t, *params.AccessSeat)
+	assert.False(t, *params.GatewaySeat)
+
+	_, held = accessUserSeats{seatUID: "seat-1", gatewaySeat: true}.release("account:acct-1:" + accessSeatEntitlement)
+	assert.False(t, held)
+}
+
+func TestAccountRevokeSeat(t *testing.T) {
+	ctx := context.Background()
+	account, user := seatTestFixtures(t, false, true)
+	entitlements := accessSeatEntitlements(account)
+	o := accountBuilder(nil, "acct-1", "", nil, nil)
+
+	// The user only holds a gateway seat, so the access seat is already released.
+	var annos annotations.Annotations
+	annos, err := o.Revoke(ctx, &v2.Grant{Entitlement: entitlements[0], Principal: user})
+	require.NoError(t, err)
+	assert.True(t, annos.Contains(&v2.GrantAlreadyRevoked{}))
+
+	member := &v2.Resource{Id: &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: "user-1"}}
+	_, err = o.Revoke(ctx, &v2.Grant{Entitlement: entitlements[1], Principal: member})
+	assert.ErrorContains(t, err, "only access users can have seats revoked")
+}
@@ -0,0 +1,45 @@
+package connector
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessServiceTokenResource(t *testing.T) {
+	body := `{
+		"id": "token-1",
+		"name": "ci-runner",
+		"client_id": "abc.access",
+		"duration": "8760h",
+		"created_at": "2024-01-02T03:04:05Z",
+		"expires_at": "2025-01-02T03:04:05Z",
+		"last_seen_at": "2024-06-07T08:09:10Z"
+	}`
+
+	var token accessServiceToken
+	require.NoError(t, json.Unmarshal([]byte(body), &token))
+	require.NotNil(t, token.LastSeenAt)
+
+	resource, err := accessServiceTokenResource(token, accountScopedID("acct-1", "acct-2", token.ID), accountResourceID("acct-2"))
+	require.NoError(t, err)
+	assert.Equal(t, "acct-2/token-1", resource.GetId().GetResource())
+	assert.Equal(t, resourceTypeAccessServiceToken.GetId(), resource.GetId().GetResourceType())
+	assert.Equal(t, "ci-runner", resource.GetDisplayName())
+
+	secretTrait := &v2.SecretTrait{}
+	annos := annotations.Annotations(resource.GetAnnotations())
+	ok, err := annos.Pick(secretTrait)
+	require.NoError(t, err)
+	require.True(t, ok, "expected a SecretTrait on the access_service_token resource")
+
+	assert.Equal(t, accessServiceTokenSecretDetail, secretTrait.GetCredentialDetail())
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), secretTrait.GetCreatedAt().AsTime())
+	assert.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), secretTrait.GetExpiresAt().AsTime())
+	assert.Equal(t, time.Date(2024, 6, 7, 8, 9, 10, 0, time.UTC), secretTrait.GetLastUsedAt().AsTime())
+}
@@ -0,0 +1,134 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudflare/cloudflare-go"
+	config "github.com/conductorone/baton-sdk/pb/c1/config/v1"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/actions"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	revokeAccessSessionsAction = "revoke_access_sessions"
+	actionResourceIDArg        = "resource_id"
+	actionAccountIDArg         = "account_id"
+)
+
+var revokeAccessSessionsSchema = &v2.BatonActionSchema{
+	Name:        revokeAccessSessionsAction,
+	DisplayName: "Revoke Access Sessions",
+	Description: "Revoke every Zero Trust Access session and token issued to the user, signing them out of all Access applications.",
+	Arguments: []*config.Field{
+		{
+			Name:        actionResourceIDArg,
+			DisplayName: "User",
+			Description: "The user or Access user whose Access sessions to revoke.",
+			IsRequired:  true,
+			Field:       &config.Field_ResourceIdField{ResourceIdField: &config.ResourceIdField{}},
+		},
+		{
+			Name:        actionAccountIDArg,
+			DisplayName: "Account ID",
+			Description: "The Cloudflare account to revoke the sessions in. Defaults to the user's account, or the configured account ID.",
+			Field:       &config.Field_StringField{StringField: &config.StringField{}},
+		},
+	},
+	ReturnTypes: []*config.Field{
+		{Name: "success", Field: &config.Field_BoolField{BoolField: &config.BoolField{}}},
+		{Name: "email", Field: &config.Field_StringField{StringField: &config.StringField{}}},
+	},
+}
+
+// revokeAccessSessions revokes the Access tokens issued to the email address, which signs the user
+// out of every Access application until they authenticate again.
+func revokeAccessSessions(ctx context.Context, client *cloudflare.API, accountID, email string) error {
+	err := client.RevokeAccessUserTokens(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.RevokeAccessUserTokensParams{Email: email})
+	if err != nil {
+		return fmt.Errorf("baton-cloudflare: failed to revoke access sessions: %w", err)
+	}
+
+	return nil
+}
+
+func (o *UserResourceType) ResourceActions(ctx context.Context, registry actions.ActionRegistry) error {
+	return registry.Register(ctx, revokeAccessSessionsSchema, o.revokeAccessSessions)
+}
+
+// ResourceActions registers revoke_access_sessions for Access users too: they hold most Access
+// sessions, and need not be members of the account.
+func (o *accessUserResourceType) ResourceActions(ctx context.Context, registry actions.ActionRegistry) error {
+	return registry.Register(ctx, revokeAccessSessionsSchema, o.revokeAccessSessions)
+}
+
+func (o *UserResourceType) revokeAccessSessions(ctx context.Context, args *structpb.Struct) (*structpb.Struct, annotations.Annotations, error) {
+	return runRevokeAccessSessions(ctx, o.client, o.accountId, args)
+}
+
+func (o *accessUserResourceType) revokeAccessSessions(ctx context.Context, args *structpb.Struct) (*structpb.Struct, annotations.Annotations, error) {
+	return runRevokeAccessSessions(ctx, o.client, o.accountId, args)
+}
+
+// runRevokeAccessSessions is the revoke_access_sessions action, for a user or an Access user. The
+// account comes from the arguments, the account-scoped resource ID, or the configuration, in that
+// order.
+func runRevokeAccessSessions(ctx context.Context, client *cloudflare.API, defaultAccountID string, args *structpb.Struct) (*structpb.Struct, annotations.Annotations, error) {
+	resourceID, err := actions.RequireResourceIDArg(args, actionResourceIDArg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: %w", err)
+	}
+	if resourceID.ResourceType != resourceTypeUser.Id && resourceID.ResourceType != resourceTypeAccessUser.Id {
+		return nil, nil, fmt.Errorf("baton-cloudflare: invalid resource type for %s: %s", revokeAccessSessionsAction, resourceID.ResourceType)
+	}
+
+	accountID, id := parseAccountScopedID(defaultAccountID, resourceID.Resource)
+	if argAccountID, ok := actions.GetStringArg(args, actionAccountIDArg); ok && argAccountID != "" {
+		accountID = argAccountID
+	}
+	if accountID == "" {
+		return nil, nil, ErrMissingAccountID
+	}
+
+	email, err := accessSessionsEmail(ctx, client, accountID, resourceID.ResourceType, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := revokeAccessSessions(ctx, client, accountID, email); err != nil {
+		return nil, nil, err
+	}
+
+	rv := &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			"success": structpb.NewBoolValue(true),
+			"email":   structpb.NewStringValue(email),
+		},
+	}
+
+	return rv, nil, nil
+}
+
+// accessSessionsEmail returns the email address Access sessions are revoked by: the account
+// member's for a user, or the Access user's own.
+func accessSessionsEmail(ctx context.Context, client *cloudflare.API, accountID, resourceType, id string) (string, error) {
+	if resourceType == resourceTypeAccessUser.Id {
+		user, found, err := findAccessUser(ctx, client, accountID, id)
+		if err != nil {
+			return "", err
+		}
+		if !found || user.Email == "" {
+			return "", fmt.Errorf("baton-cloudflare: access user %s not found", id)
+		}
+		return user.Email, nil
+	}
+
+	member, err := findMemberByUserID(ctx, client, nil, accountID, id)
+	if err != nil {
+		return "", err
+	}
+
+	return member.User.Email, nil
+}
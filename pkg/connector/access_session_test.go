@@ -0,0 +1,81 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/conductorone/baton-sdk/pkg/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestRevokeAccessSessionsAction(t *testing.T) {
+	ctx := context.Background()
+	manager := actions.NewActionManager(ctx)
+	registry, err := manager.GetTypeRegistry(ctx, resourceTypeUser.Id)
+	require.NoError(t, err)
+
+	o := userBuilder(nil, "", true)
+	require.NoError(t, o.ResourceActions(ctx, registry))
+
+	schemas, _, err := manager.ListActionSchemas(ctx, resourceTypeUser.Id)
+	require.NoError(t, err)
+	require.Len(t, schemas, 1)
+	assert.Equal(t, revokeAccessSessionsAction, schemas[0].GetName())
+
+	args, err := structpb.NewStruct(map[string]interface{}{
+		actionResourceIDArg: map[string]interface{}{"resource_type_id": resourceTypeRole.Id, "resource_id": "role-1"},
+	})
+	require.NoError(t, err)
+	_, _, err = o.revokeAccessSessions(ctx, args)
+	assert.ErrorContains(t, err, "invalid resource type")
+
+	args, err = structpb.NewStruct(map[string]interface{}{
+		actionResourceIDArg: map[string]interface{}{"resource_type_id": resourceTypeUser.Id, "resource_id": "user-1"},
+	})
+	require.NoError(t, err)
+	_, _, err = o.revokeAccessSessions(ctx, args)
+	assert.ErrorIs(t, err, ErrMissingAccountID)
+}
+
+// Access users are signed out by the email on their Access user record.
+func TestRevokeAccessSessionsAccessUser(t *testing.T) {
+	var revoked cloudflare.RevokeAccessUserTokensParams
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/accounts/acct-1/access/users":
+			_, _ = w.Write([]byte(`{"success":true,"result":[{"id":"access-user-1","email":"contractor@partner.com"}],"result_info":{"page":1,"per_page":25,"count":1,"total_count":1}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/accounts/acct-1/access/organizations/revoke_user":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&revoked))
+			_, _ = w.Write([]byte(`{"success":true,"result":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := cloudflare.NewWithAPIToken("test-token", cloudflare.BaseURL(server.URL))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	manager := actions.NewActionManager(ctx)
+	registry, err := manager.GetTypeRegistry(ctx, resourceTypeAccessUser.Id)
+	require.NoError(t, err)
+	o := accessUserBuilder(client, "acct-1")
+	require.NoError(t, o.ResourceActions(ctx, registry))
+
+	args, err := structpb.NewStruct(map[string]interface{}{
+		actionResourceIDArg: map[string]interface{}{"resource_type_id": resourceTypeAccessUser.Id, "resource_id": "access-user-1"},
+	})
+	require.NoError(t, err)
+	rv, _, err := o.revokeAccessSessions(ctx, args)
+	require.NoError(t, err)
+	assert.Equal(t, "contractor@partner.com", rv.GetFields()["email"].GetStringValue())
+	assert.Equal(t, "contractor@partner.com", revoked.Email)
+}
@@ -0,0 +1,433 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/session"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	accessUsersPerPage = 50
+	// accessUserIdentityLookups bounds how many last seen identity requests run at once.
+	accessUserIdentityLookups            = 5
+	accessIdentityProviderNamesKeyPrefix = "access_identity_provider_names"
+	// accessUserSnapshotKeyPrefix keys the sync's copy of each account's Access users, which
+	// Access group, application and policy grants match by email.
+	accessUserSnapshotKeyPrefix = "account_access_users"
+)
+
+type accessUserResourceType struct {
+	resourceType *v2.ResourceType
+	client       *cloudflare.API
+	accountId    string
+}
+
+func (o *accessUserResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+// parseAccessUserTime parses the RFC 3339 timestamps the Access users endpoint returns as strings.
+func parseAccessUserTime(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// accessUserLastSeenIdentity adds the IdP groups, which cloudflare-go's
+// GetAccessUserLastSeenIdentityResult omits, to the identity an Access user last logged in with.
+type accessUserLastSeenIdentity struct {
+	cloudflare.GetAccessUserLastSeenIdentityResult
+	Groups []accessUserIdentityGroup `json:"groups"`
+}
+
+type accessUserIdentityGroup struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// accessUserIdentity is the identity provider an Access user last authenticated with, and the
+// groups that provider reported for them.
+type accessUserIdentity struct {
+	IDPID   string
+	IDPName string
+	IDPType string
+	Groups  []string
+}
+
+// newAccessUserIdentity resolves the last seen identity's provider ID to its name; providers that
+// have since been deleted keep only their ID and type.
+func newAccessUserIdentity(lastSeen accessUserLastSeenIdentity, idpNames map[string]string) *accessUserIdentity {
+	groups := make([]string, 0, len(lastSeen.Groups))
+	for _, group := range lastSeen.Groups {
+		switch {
+		case group.Name != "":
+			groups = append(groups, group.Name)
+		case group.Email != "":
+			groups = append(groups, group.Email)
+		default:
+			groups = append(groups, group.ID)
+		}
+	}
+
+	return &accessUserIdentity{
+		IDPID:   lastSeen.IDP.ID,
+		IDPName: idpNames[lastSeen.IDP.ID],
+		IDPType: lastSeen.IDP.Type,
+		Groups:  groups,
+	}
+}
+
+// accessUserResourceStatus reports Access users holding an Access or Gateway seat as enabled.
+// Cloudflare has no other state for Access users: a user without a seat cannot reach Access
+// applications or Gateway until a login assigns them one.
+func accessUserResourceStatus(user cloudflare.AccessUser) (v2.Status_ResourceStatus, string) {
+	if (user.AccessSeat != nil && *user.AccessSeat) || (user.GatewaySeat != nil && *user.GatewaySeat) {
+		return v2.Status_RESOURCE_STATUS_ENABLED, "seat assigned"
+	}
+
+	return v2.Status_RESOURCE_STATUS_DISABLED, "no seat"
+}
+
+// accessUserResource builds a Zero Trust user. Access users are everyone who has logged in to an
+// Access application or enrolled a device, whether or not they are members of the account. The
+// identity, when known, records which identity provider they last logged in through.
+func accessUserResource(user cloudflare.AccessUser, identity *accessUserIdentity, resourceID string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"access_user_id":        user.ID,
+		"email":                 user.Email,
+		"name":                  user.Name,
+		"access_seat":           user.AccessSeat != nil && *user.AccessSeat,
+		"gateway_seat":          user.GatewaySeat != nil && *user.GatewaySeat,
+		"seat_uid":              user.SeatUID,
+		"active_device_count":   user.ActiveDeviceCount,
+		"last_successful_login": user.LastSuccessfulLogin,
+	}
+	if identity != nil {
+		groups := make([]interface{}, 0, len(identity.Groups))
+		for _, group := range identity.Groups {
+			groups = append(groups, group)
+		}
+		profile["last_idp_id"] = identity.IDPID
+		profile["last_idp_name"] = identity.IDPName
+		profile["last_idp_type"] = identity.IDPType
+		profile["idp_groups"] = groups
+	}
+
+	userTraits := []rs.UserTraitOption{
+		rs.WithUserLogin(user.Email),
+		rs.WithEmail(user.Email, true),
+	}
+	if lastLogin, ok := parseAccessUserTime(user.LastSuccessfulLogin); ok {
+		userTraits = append(userTraits, rs.WithLastLogin(lastLogin))
+	}
+	if createdAt, ok := parseAccessUserTime(user.CreatedAt); ok {
+		userTraits = append(userTraits, rs.WithCreatedAt(createdAt))
+	}
+
+	displayName := user.Name
+	if displayName == "" {
+		displayName = user.Email
+	}
+
+	status, statusDetails := accessUserResourceStatus(user)
+
+	return rs.NewUserResource(
+		displayName,
+		resourceTypeAccessUser,
+		resourceID,
+		userTraits,
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithResourceProfile(profile),
+		rs.WithResourceStatus(status, statusDetails),
+	)
+}
+
+func (o *accessUserResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	page, err := convertPageToken(opts.PageToken.Token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: invalid page token: %w", err)
+	}
+
+	users, _, err := o.client.ListAccessUsers(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.AccessUserParams{
+		ResultInfo: cloudflare.ResultInfo{Page: page, PerPage: accessUsersPerPage},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: failed to list access users: %w", err)
+	}
+
+	idpNames, err := o.identityProviderNames(ctx, opts.Session, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	identities := o.lastSeenIdentities(ctx, accountID, users, idpNames)
+	rv := make([]*v2.Resource, 0, len(users))
+	for i, user := range users {
+		ur, err := accessUserResource(user, identities[i], accountScopedID(o.accountId, accountID, user.ID), parentResourceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		rv = append(rv, ur)
+	}
+
+	nextPage := convertNextPageToken(page, len(users))
+
+	return rv, &rs.SyncOpResults{NextPageToken: nextPage}, nil
+}
+
+func (o *accessUserResourceType) Entitlements(_ context.Context, _ *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
+	return nil, &rs.SyncOpResults{}, nil
+}
+
+func (o *accessUserResourceType) Grants(_ context.Context, _ *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Grant, *rs.SyncOpResults, error) {
+	return nil, &rs.SyncOpResults{}, nil
+}
+
+// identityProviderNames maps the account's identity provider IDs to their names. During a sync the
+// providers are listed once per account and kept in the session store, so every page of users
+// reads the same map. Without access to the providers, users still record the provider ID and type.
+func (o *accessUserResourceType) identityProviderNames(ctx context.Context, ss sessions.SessionStore, accountID string) (map[string]string, error) {
+	key := fmt.Sprintf("%s:%s", accessIdentityProviderNamesKeyPrefix, accountID)
+	if ss != nil {
+		names, found, err := session.GetJSON[map[string]string](ctx, ss, key)
+		if err != nil {
+			return nil, fmt.Errorf("baton-cloudflare: failed to read identity provider names: %w", err)
+		}
+		if found {
+			return names, nil
+		}
+	}
+
+	names := make(map[string]string)
+	idps, _, err := o.client.ListAccessIdentityProviders(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.ListAccessIdentityProvidersParams{})
+	if err != nil {
+		// The empty map is stored too, so the failure is logged once per account rather than per page.
+		ctxzap.Extract(ctx).Warn(
+			"baton-cloudflare: failed to list access identity providers, skipping provider names",
+			zap.String("account_id", accountID),
+			zap.Error(err),
+		)
+	}
+	for _, idp := range idps {
+		names[idp.ID] = idp.Name
+	}
+
+	if ss != nil {
+		if err := session.SetJSON(ctx, ss, key, names); err != nil {
+			return nil, fmt.Errorf("baton-cloudflare: failed to store identity provider names: %w", err)
+		}
+	}
+
+	return names, nil
+}
+
+// lastSeenIdentities looks up the identity each user last logged in with, indexed like users.
+// Cloudflare has no bulk endpoint, so each user who has logged in costs a request; at most
+// accessUserIdentityLookups of them run at once.
+func (o *accessUserResourceType) lastSeenIdentities(ctx context.Context, accountID string, users []cloudflare.AccessUser, idpNames map[string]string) []*accessUserIdentity {
+	rv := make([]*accessUserIdentity, len(users))
+	limit := make(chan struct{}, accessUserIdentityLookups)
+	var wg sync.WaitGroup
+	for i, user := range users {
+		if user.LastSuccessfulLogin == "" {
+			continue
+		}
+
+		wg.Add(1)
+		limit <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-limit }()
+			rv[i] = o.lastSeenIdentity(ctx, accountID, user, idpNames)
+		}()
+	}
+	wg.Wait()
+
+	return rv
+}
+
+// lastSeenIdentity returns the identity the user last logged in with, or nil when they have never
+// logged in or it cannot be read; the rest of the user still syncs. cloudflare-go's
+// GetAccessUserLastSeenIdentity drops the IdP groups, so the same endpoint is read raw.
+func (o *accessUserResourceType) lastSeenIdentity(ctx context.Context, accountID string, user cloudflare.AccessUser, idpNames map[string]string) *accessUserIdentity {
+	if user.LastSuccessfulLogin == "" {
+		return nil
+	}
+
+	l := ctxzap.Extract(ctx)
+	resp, err := o.client.Raw(ctx, http.MethodGet, fmt.Sprintf("/accounts/%s/access/users/%s/last_seen_identity", accountID, user.ID), nil, nil)
+	if err != nil {
+		l.Warn(
+			"baton-cloudflare: failed to get access user last seen identity",
+			zap.String("access_user_id", user.ID),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	var lastSeen accessUserLastSeenIdentity
+	if err := json.Unmarshal(resp.Result, &lastSeen); err != nil {
+		l.Warn(
+			"baton-cloudflare: failed to decode access user last seen identity",
+			zap.String("access_user_id", user.ID),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	return newAccessUserIdentity(lastSeen, idpNames)
+}
+
+func accessUserSnapshotKey(accountID string) string {
+	return fmt.Sprintf("%s:%s", accessUserSnapshotKeyPrefix, accountID)
+}
+
+func accessUserSnapshotPageKey(accountID string, page int) string {
+	return fmt.Sprintf("%s:%s:%d", accessUserSnapshotKeyPrefix, accountID, page)
+}
+
+// accessUsersSnapshot returns every Access user of the account. During a sync the users are listed
+// once and kept in the session store one API page per key, like the member snapshot; without one
+// they are listed directly. Credentials without access to Zero Trust users get an empty list, so
+// grants to account members still sync.
+func accessUsersSnapshot(ctx context.Context, client *cloudflare.API, ss sessions.SessionStore, accountID string) ([]cloudflare.AccessUser, error) {
+	if ss != nil {
+		users, found, err := getAccessUserSnapshot(ctx, ss, accountID)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return users, nil
+		}
+	}
+
+	var pages [][]cloudflare.AccessUser
+	for page := 1; ; page++ {
+		listed, _, err := client.ListAccessUsers(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.AccessUserParams{
+			ResultInfo: cloudflare.ResultInfo{Page: page, PerPage: accessUsersPerPage},
+		})
+		if err != nil {
+			var authzErr *cloudflare.AuthorizationError
+			if !errors.As(err, &authzErr) {
+				return nil, fmt.Errorf("baton-cloudflare: failed to list access users: %w", err)
+			}
+			// The empty snapshot is stored too, so the failure is logged once per account.
+			ctxzap.Extract(ctx).Warn(
+				"baton-cloudflare: not authorized to list access users, skipping access user grants",
+				zap.String("account_id", accountID),
+				zap.Error(err),
+			)
+			pages = nil
+			break
+		}
+		pages = append(pages, listed)
+		if len(listed) < accessUsersPerPage {
+			break
+		}
+	}
+
+	if ss != nil {
+		if err := setAccessUserSnapshot(ctx, ss, accountID, pages); err != nil {
+			return nil, err
+		}
+	}
+
+	var users []cloudflare.AccessUser
+	for _, page := range pages {
+		users = append(users, page...)
+	}
+
+	return users, nil
+}
+
+// getAccessUserSnapshot reads a stored Access user snapshot. A snapshot with missing pages is
+// reported as not found so the caller lists the users again.
+func getAccessUserSnapshot(ctx context.Context, ss sessions.SessionStore, accountID string) ([]cloudflare.AccessUser, bool, error) {
+	index, found, err := session.GetJSON[snapshotIndex](ctx, ss, accessUserSnapshotKey(accountID))
+	if err != nil {
+		return nil, false, fmt.Errorf("baton-cloudflare: failed to read access user snapshot: %w", err)
+	}
+	if !found {
+		return nil, false, nil
+	}
+
+	keys := make([]string, 0, index.Pages)
+	for page := 1; page <= index.Pages; page++ {
+		keys = append(keys, accessUserSnapshotPageKey(accountID, page))
+	}
+
+	pages, err := session.GetManyJSON[[]cloudflare.AccessUser](ctx, ss, keys)
+	if err != nil {
+		return nil, false, fmt.Errorf("baton-cloudflare: failed to read access user snapshot: %w", err)
+	}
+	if len(pages) != len(keys) {
+		return nil, false, nil
+	}
+
+	users := []cloudflare.AccessUser{}
+	for _, key := range keys {
+		users = append(users, pages[key]...)
+	}
+
+	return users, true, nil
+}
+
+func setAccessUserSnapshot(ctx context.Context, ss sessions.SessionStore, accountID string, pages [][]cloudflare.AccessUser) error {
+	if len(pages) > 0 {
+		items := make(map[string][]cloudflare.AccessUser, len(pages))
+		for i, page := range pages {
+			items[accessUserSnapshotPageKey(accountID, i+1)] = page
+		}
+		if err := session.SetManyJSON(ctx, ss, items); err != nil {
+			return fmt.Errorf("baton-cloudflare: failed to store access user snapshot: %w", err)
+		}
+	}
+
+	err := session.SetJSON(ctx, ss, accessUserSnapshotKey(accountID), snapshotIndex{Pages: len(pages)})
+	if err != nil {
+		return fmt.Errorf("baton-cloudflare: failed to store access user snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// accessUserResourceID returns the ID of an Access user principal, for grants that only need to
+// name the user.
+func accessUserResourceID(defaultAccountID, accountID string, user cloudflare.AccessUser) *v2.ResourceId {
+	return &v2.ResourceId{
+		ResourceType: resourceTypeAccessUser.Id,
+		Resource:     accountScopedID(defaultAccountID, accountID, user.ID),
+	}
+}
+
+func accessUserBuilder(client *cloudflare.API, accountId string) *accessUserResourceType {
+	return &accessUserResourceType{
+		resourceType: resourceTypeAccessUser,
+		client:       client,
+		accountId:    accountId,
+	}
+}
@@ -0,0 +1,121 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/session"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessUserResource(t *testing.T) {
+	accessSeat := true
+	user := cloudflare.AccessUser{
+		ID:                  "access-user-1",
+		Email:               "jane@example.com",
+		AccessSeat:          &accessSeat,
+		ActiveDeviceCount:   2,
+		LastSuccessfulLogin: "2024-05-06T07:08:09Z",
+		CreatedAt:           "2023-01-02T03:04:05Z",
+	}
+
+	resource, err := accessUserResource(user, nil, user.ID, accountResourceID("acct-1"))
+	require.NoError(t, err)
+	assert.Equal(t, resourceTypeAccessUser.GetId(), resource.GetId().GetResourceType())
+	assert.Equal(t, "jane@example.com", resource.GetDisplayName())
+
+	profile := resource.GetProfile().AsMap()
+	assert.Equal(t, true, profile["access_seat"])
+	assert.Equal(t, false, profile["gateway_seat"])
+	assert.Equal(t, float64(2), profile["active_device_count"])
+	assert.Equal(t, v2.Status_RESOURCE_STATUS_ENABLED, resource.GetStatus().GetStatus())
+
+	userTrait, err := rs.GetUserTrait(resource)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC), userTrait.GetLastLogin().AsTime())
+	assert.Equal(t, time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC), userTrait.GetCreatedAt().AsTime())
+}
+
+func TestAccessUserResourceWithoutLogin(t *testing.T) {
+	resource, err := accessUserResource(cloudflare.AccessUser{ID: "access-user-1", Email: "jane@example.com"}, nil, "access-user-1", nil)
+	require.NoError(t, err)
+
+	userTrait, err := rs.GetUserTrait(resource)
+	require.NoError(t, err)
+	assert.Nil(t, userTrait.GetLastLogin())
+	assert.Equal(t, v2.Status_RESOURCE_STATUS_DISABLED, resource.GetStatus().GetStatus())
+}
+
+func TestAccessUserResourceIdentity(t *testing.T) {
+	body := `{
+		"email": "jane@example.com",
+		"idp": {"id": "idp-2", "type": "onetimepin"},
+		"groups": [
+			{"id": "g-1", "name": "Engineering"},
+			{"id": "g-2", "email": "oncall@example.com"}
+		]
+	}`
+
+	var lastSeen accessUserLastSeenIdentity
+	require.NoError(t, json.Unmarshal([]byte(body), &lastSeen))
+
+	identity := newAccessUserIdentity(lastSeen, map[string]string{"idp-1": "Okta", "idp-2": "One-time PIN"})
+	assert.Equal(t, "One-time PIN", identity.IDPName)
+	assert.Equal(t, []string{"Engineering", "oncall@example.com"}, identity.Groups)
+
+	resource, err := accessUserResource(cloudflare.AccessUser{ID: "access-user-1", Email: "jane@example.com"}, identity, "access-user-1", nil)
+	require.NoError(t, err)
+
+	profile := resource.GetProfile().AsMap()
+	assert.Equal(t, "idp-2", profile["last_idp_id"])
+	assert.Equal(t, "onetimepin", profile["last_idp_type"])
+	assert.Equal(t, []interface{}{"Engineering", "oncall@example.com"}, profile["idp_groups"])
+}
+
+func TestIdentityProviderNamesFromSession(t *testing.T) {
+	ctx := context.Background()
+	ss := newMapSessionStore()
+	require.NoError(t, session.SetJSON(ctx, ss, accessIdentityProviderNamesKeyPrefix+":acct-1", map[string]string{"idp-1": "Okta"}))
+
+	// Stored names are read without calling Cloudflare.
+	o := accessUserBuilder(nil, "acct-1")
+	names, err := o.identityProviderNames(ctx, ss, "acct-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"idp-1": "Okta"}, names)
+}
+
+func TestLastSeenIdentitiesWithoutLogin(t *testing.T) {
+	o := accessUserBuilder(nil, "acct-1")
+
+	// Users who have never logged in have no identity to look up.
+	identities := o.lastSeenIdentities(context.Background(), "acct-1", []cloudflare.AccessUser{{ID: "access-user-1"}, {ID: "access-user-2"}}, nil)
+	assert.Equal(t, []*accessUserIdentity{nil, nil}, identities)
+}
+
+func TestAccessUsersSnapshotFromSession(t *testing.T) {
+	ctx := context.Background()
+	ss := newMapSessionStore()
+	pages := [][]cloudflare.AccessUser{
+		{{ID: "access-user-1", Email: "jane@example.com"}},
+		{{ID: "access-user-2", Email: "contractor@partner.com"}},
+	}
+	require.NoError(t, setAccessUserSnapshot(ctx, ss, "acct-1", pages))
+
+	// A stored snapshot is read without calling Cloudflare.
+	users, err := accessUsersSnapshot(ctx, nil, ss, "acct-1")
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "access-user-2", users[1].ID)
+
+	// An empty snapshot, stored when the users cannot be listed, is found too.
+	require.NoError(t, setAccessUserSnapshot(ctx, ss, "acct-2", nil))
+	users, err = accessUsersSnapshot(ctx, nil, ss, "acct-2")
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
@@ -0,0 +1,245 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const (
+	accountsPerPage = 50
+	// accountMemberEntitlement and accountTwoFactorEntitlement are read-only: together they show
+	// which members of the account have not enabled two-factor authentication.
+	accountMemberEntitlement    = "member"
+	accountTwoFactorEntitlement = "two_factor_enabled"
+	// accountScopedIDSeparator joins an account ID onto the ID of a resource that repeats across accounts.
+	accountScopedIDSeparator = "/"
+)
+
+// accountChildResourceTypes are synced once per account, under the account resource.
+var accountChildResourceTypes = []*v2.ResourceType{
+	resourceTypeUser,
+	resourceTypeInvitation,
+	resourceTypeRole,
+	resourceTypeGroup,
+	resourceTypeAccessGroup,
+	resourceTypeAccessApplication,
+	resourceTypeAccessUser,
+	resourceTypeAccessServiceToken,
+	resourceTypeAccessIdentityProvider,
+	resourceTypeDevice,
+	resourceTypePolicy,
+	resourceTypePermissionGroup,
+	resourceTypeZone,
+	resourceTypeAPIToken,
+	resourceTypeTokenPermissionGroup,
+}
+
+type accountResourceType struct {
+	resourceType   *v2.ResourceType
+	client         *cloudflare.API
+	accountId      string
+	accountIds     []string
+	skipAccountIds []string
+	// apiTokens creates the account API tokens issued as the account's credentials.
+	apiTokens *apiTokenResourceType
+}
+
+func (o *accountResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+func accountResource(account cloudflare.Account) (*v2.Resource, error) {
+	enforceTwoFactor := account.Settings != nil && account.Settings.EnforceTwoFactor
+	profile := map[string]interface{}{
+		"account_id":         account.ID,
+		"account_name":       account.Name,
+		"account_type":       account.Type,
+		"enforce_two_factor": enforceTwoFactor,
+	}
+
+	opts := []rs.ResourceOption{
+		rs.WithResourceProfile(profile),
+	}
+	for _, rt := range accountChildResourceTypes {
+		opts = append(opts, rs.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: rt.Id}))
+	}
+	if !account.CreatedOn.IsZero() {
+		opts = append(opts, rs.WithResourceCreatedAt(account.CreatedOn))
+	}
+
+	displayName := account.Name
+	if displayName == "" {
+		displayName = account.ID
+	}
+
+	return rs.NewResource(displayName, resourceTypeAccount, account.ID, opts...)
+}
+
+func (o *accountResourceType) skipped(accountID string) bool {
+	return slices.Contains(o.skipAccountIds, accountID)
+}
+
+// List returns the accounts to sync. An explicit allow-list is fetched account by account so a
+// missing or inaccessible account fails the sync instead of silently disappearing; otherwise every
+// account visible to the credentials is listed.
+func (o *accountResourceType) List(ctx context.Context, _ *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	if len(o.accountIds) > 0 {
+		rv := make([]*v2.Resource, 0, len(o.accountIds))
+		for _, accountID := range o.accountIds {
+			if o.skipped(accountID) {
+				continue
+			}
+			account, _, err := o.client.Account(ctx, accountID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("baton-cloudflare: could not retrieve account %s: %w", accountID, err)
+			}
+			ar, err := accountResource(account)
+			if err != nil {
+				return nil, nil, err
+			}
+			rv = append(rv, ar)
+		}
+
+		return rv, &rs.SyncOpResults{}, nil
+	}
+
+	page, err := convertPageToken(opts.PageToken.Token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: invalid page token error")
+	}
+
+	accounts, resp, err := o.client.Accounts(ctx, cloudflare.AccountsListParams{
+		PaginationOptions: cloudflare.PaginationOptions{Page: page, PerPage: accountsPerPage},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: could not retrieve accounts: %w", err)
+	}
+
+	nextPage := convertNextPageToken(resp.Page, len(accounts))
+	rv := make([]*v2.Resource, 0, len(accounts))
+	for _, account := range accounts {
+		if o.skipped(account.ID) {
+			continue
+		}
+		ar, err := accountResource(account)
+		if err != nil {
+			return nil, nil, err
+		}
+		rv = append(rv, ar)
+	}
+
+	return rv, &rs.SyncOpResults{NextPageToken: nextPage}, nil
+}
+
+func (o *accountResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
+	rv := []*v2.Entitlement{
+		ent.NewPermissionEntitlement(
+			resource,
+			accountMemberEntitlement,
+			ent.WithGrantableTo(resourceTypeUser),
+			ent.WithDisplayName(fmt.Sprintf("%s Member", resource.DisplayName)),
+			ent.WithDescription(fmt.Sprintf("Is a member of the %s Cloudflare account", resource.DisplayName)),
+			ent.WithAnnotation(&v2.EntitlementImmutable{}),
+		),
+		ent.NewPermissionEntitlement(
+			resource,
+			accountTwoFactorEntitlement,
+			ent.WithGrantableTo(resourceTypeUser),
+			ent.WithDisplayName(fmt.Sprintf("%s Two-Factor Authentication Enabled", resource.DisplayName)),
+			ent.WithDescription(fmt.Sprintf("Has two-factor authentication enabled as a member of the %s Cloudflare account", resource.DisplayName)),
+			ent.WithAnnotation(&v2.EntitlementImmutable{}),
+		),
+	}
+	rv = append(rv, accessSeatEntitlements(resource)...)
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// Grants grants the member entitlement to every accepted member of the account, and the
+// two_factor_enabled entitlement to those with two-factor authentication turned on. The seat
+// entitlements are granted to the Access users holding each Zero Trust seat.
+func (o *accountResourceType) Grants(ctx context.Context, resource *v2.Resource, opts rs.SyncOpAttrs) ([]*v2.Grant, *rs.SyncOpResults, error) {
+	accountID := resource.Id.Resource
+	members, err := accountMembersSnapshot(ctx, o.client, opts.Session, accountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: could not retrieve account members: %w", err)
+	}
+
+	var rv []*v2.Grant
+	for _, member := range members {
+		// Pending invitations have no User.ID yet.
+		if member.User.ID == "" {
+			continue
+		}
+
+		ur, err := userResource(member, accountScopedID(o.accountId, accountID, member.User.ID), resource.Id)
+		if err != nil {
+			return nil, nil, wrapError(err, "failed to create user resource")
+		}
+		rv = append(rv, grant.NewGrant(resource, accountMemberEntitlement, ur.Id, grant.WithAnnotation(&v2.GrantImmutable{})))
+		if member.User.TwoFactorAuthenticationEnabled {
+			rv = append(rv, grant.NewGrant(resource, accountTwoFactorEntitlement, ur.Id, grant.WithAnnotation(&v2.GrantImmutable{})))
+		}
+	}
+
+	seatGrants, err := o.accessSeatGrants(ctx, resource)
+	if err != nil {
+		return nil, nil, err
+	}
+	rv = append(rv, seatGrants...)
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+func accountResourceID(accountID string) *v2.ResourceId {
+	return &v2.ResourceId{ResourceType: resourceTypeAccount.Id, Resource: accountID}
+}
+
+// accountIDFromParent returns the account a child resource is being synced under.
+func accountIDFromParent(parentResourceID *v2.ResourceId) (string, bool) {
+	if parentResourceID == nil || parentResourceID.ResourceType != resourceTypeAccount.Id || parentResourceID.Resource == "" {
+		return "", false
+	}
+
+	return parentResourceID.Resource, true
+}
+
+// accountScopedID qualifies the ID of a resource whose Cloudflare ID repeats across accounts, such
+// as built-in roles and permission groups. Resources in the configured account keep their bare ID
+// so that resources synced before multi-account support keep their identity.
+func accountScopedID(defaultAccountID, accountID, id string) string {
+	if accountID == defaultAccountID {
+		return id
+	}
+
+	return accountID + accountScopedIDSeparator + id
+}
+
+// parseAccountScopedID is the inverse of accountScopedID; bare IDs belong to the configured account.
+func parseAccountScopedID(defaultAccountID, id string) (string, string) {
+	accountID, objectID, ok := strings.Cut(id, accountScopedIDSeparator)
+	if !ok {
+		return defaultAccountID, id
+	}
+
+	return accountID, objectID
+}
+
+func accountBuilder(client *cloudflare.API, accountId, emailId string, accountIds, skipAccountIds []string) *accountResourceType {
+	return &accountResourceType{
+		resourceType:   resourceTypeAccount,
+		client:         client,
+		accountId:      accountId,
+		accountIds:     accountIds,
+		skipAccountIds: skipAccountIds,
+		apiTokens:      apiTokenBuilder(client, accountId, emailId),
+	}
+}
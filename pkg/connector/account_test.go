@@ -0,0 +1,94 @@
+package connector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountResource(t *testing.T) {
+	account := cloudflare.Account{
+		ID:        "acct-1",
+		Name:      "Production",
+		Type:      "enterprise",
+		CreatedOn: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	resource, err := accountResource(account)
+	require.NoError(t, err)
+	assert.Equal(t, "acct-1", resource.GetId().GetResource())
+	assert.Equal(t, resourceTypeAccount.GetId(), resource.GetId().GetResourceType())
+	assert.Equal(t, "Production", resource.GetDisplayName())
+
+	var childTypes []string
+	for _, a := range resource.GetAnnotations() {
+		child := &v2.ChildResourceType{}
+		if a.UnmarshalTo(child) == nil {
+			childTypes = append(childTypes, child.GetResourceTypeId())
+		}
+	}
+	assert.Contains(t, childTypes, resourceTypeUser.GetId())
+	assert.Contains(t, childTypes, resourceTypeRole.GetId())
+	assert.Contains(t, childTypes, resourceTypeAPIToken.GetId())
+}
+
+func TestAccountScopedID(t *testing.T) {
+	// The configured account keeps bare IDs so existing role resources are unchanged.
+	assert.Equal(t, "role-1", accountScopedID("acct-1", "acct-1", "role-1"))
+	assert.Equal(t, "acct-2/role-1", accountScopedID("acct-1", "acct-2", "role-1"))
+	assert.Equal(t, "acct-2/role-1", accountScopedID("", "acct-2", "role-1"))
+
+	accountID, id := parseAccountScopedID("acct-1", "role-1")
+	assert.Equal(t, "acct-1", accountID)
+	assert.Equal(t, "role-1", id)
+
+	accountID, id = parseAccountScopedID("acct-1", "acct-2/pg-1:com.cloudflare.api.account.zone.zone-1")
+	assert.Equal(t, "acct-2", accountID)
+	assert.Equal(t, "pg-1:com.cloudflare.api.account.zone.zone-1", id)
+}
+
+func TestAccountIDFromParent(t *testing.T) {
+	accountID, ok := accountIDFromParent(accountResourceID("acct-1"))
+	assert.True(t, ok)
+	assert.Equal(t, "acct-1", accountID)
+
+	_, ok = accountIDFromParent(nil)
+	assert.False(t, ok)
+
+	_, ok = accountIDFromParent(&v2.ResourceId{ResourceType: resourceTypeZone.Id, Resource: "zone-1"})
+	assert.False(t, ok)
+}
+
+func TestAccountResourceEnforceTwoFactor(t *testing.T) {
+	enforced, err := accountResource(cloudflare.Account{
+		ID:       "acct-1",
+		Settings: &cloudflare.AccountSettings{EnforceTwoFactor: true},
+	})
+	require.NoError(t, err)
+	assert.True(t, enforced.GetProfile().GetFields()["enforce_two_factor"].GetBoolValue())
+
+	// Accounts listed without settings are reported as not enforcing 2FA.
+	unset, err := accountResource(cloudflare.Account{ID: "acct-2"})
+	require.NoError(t, err)
+	assert.False(t, unset.GetProfile().GetFields()["enforce_two_factor"].GetBoolValue())
+	assert.Equal(t, "acct-2", unset.GetDisplayName())
+}
+
+func TestAccessSeatEntitlements(t *testing.T) {
+	resource, err := accountResource(cloudflare.Account{ID: "acct-1", Name: "Production"})
+	require.NoError(t, err)
+
+	entitlements := accessSeatEntitlements(resource)
+	require.Len(t, entitlements, 2)
+	for _, entitlement := range entitlements {
+		assert.True(t, isSeatEntitlement(entitlement))
+		assert.Equal(t, []*v2.ResourceType{resourceTypeAccessUser}, entitlement.GetGrantableTo())
+	}
+
+	assert.False(t, isSeatEntitlement(ent.NewPermissionEntitlement(resource, accountMemberEntitlement)))
+}
@@ -5,21 +5,62 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cloudflare/cloudflare-go"
+	config "github.com/conductorone/baton-sdk/pb/c1/config/v1"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/actions"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/session"
 	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 const (
 	// apiTokenSecretDetail is the §2.8 axis-2 detail string for account-owned API tokens.
 	apiTokenSecretDetail = "cloudflare.account_api_token" //nolint:gosec // axis-2 detail label, not a credential value
 	apiTokensPerPage     = 50
+	// apiTokenSnapshotKeyPrefix keys the sync's copy of each account's API tokens, which the
+	// token permission group grants read instead of listing the tokens once per permission group.
+	apiTokenSnapshotKeyPrefix = "account_api_tokens"
+	// apiTokenScopePrefix starts every API token resource scope, such as
+	// com.cloudflare.api.account.zone.<zone_id>.
+	apiTokenScopePrefix = "com.cloudflare.api."
+
+	disableTokenAction     = "disable_token"
+	apiTokenStatusActive   = "active"
+	apiTokenStatusDisabled = "disabled"
+	apiTokenStatusExpired  = "expired"
 )
 
+var disableTokenSchema = &v2.BatonActionSchema{
+	Name:        disableTokenAction,
+	DisplayName: "Disable Token",
+	Description: "Disable the API token so Cloudflare rejects it, without deleting it. The token can be re-enabled from the Cloudflare dashboard.",
+	Arguments: []*config.Field{
+		{
+			Name:        actionResourceIDArg,
+			DisplayName: "API Token",
+			Description: "The API token to disable.",
+			IsRequired:  true,
+			Field:       &config.Field_ResourceIdField{ResourceIdField: &config.ResourceIdField{}},
+		},
+	},
+	ReturnTypes: []*config.Field{
+		{Name: "success", Field: &config.Field_BoolField{BoolField: &config.BoolField{}}},
+		{Name: "status", Field: &config.Field_StringField{StringField: &config.StringField{}}},
+	},
+}
+
 type apiTokenResourceType struct {
 	resourceType *v2.ResourceType
 	client       *cloudflare.API
@@ -32,25 +73,233 @@ func (o *apiTokenResourceType) ResourceType(_ context.Context) *v2.ResourceType
 	return o.resourceType
 }
 
+// accountAPITokenResult is a decoded response from the account tokens endpoints.
+type accountAPITokenResult interface {
+	responseError(operation string) error
+}
+
+// accountAPITokenResponseStatus is the success flag and errors every account tokens response
+// carries.
+type accountAPITokenResponseStatus struct {
+	Success bool                      `json:"success"`
+	Errors  []cloudflare.ResponseInfo `json:"errors"`
+}
+
+func (r *accountAPITokenResponseStatus) responseError(operation string) error {
+	if r.Success {
+		return nil
+	}
+	if len(r.Errors) > 0 {
+		return fmt.Errorf("baton-cloudflare: %s failed: %s (code %d)", operation, r.Errors[0].Message, r.Errors[0].Code)
+	}
+
+	return fmt.Errorf("baton-cloudflare: %s failed: unknown error", operation)
+}
+
+// accountAPIToken adds the last-used time, which cloudflare-go's APIToken omits, to an account
+// API token.
+type accountAPIToken struct {
+	cloudflare.APIToken
+	LastUsedOn *time.Time `json:"last_used_on,omitempty"`
+}
+
 // accountAPITokenListResponse models GET /accounts/{account_id}/tokens. Cloudflare
 // returns token metadata only; the secret value is never present on list responses.
 type accountAPITokenListResponse struct {
-	Result     []cloudflare.APIToken     `json:"result"`
-	ResultInfo cloudflare.ResultInfo     `json:"result_info"`
-	Success    bool                      `json:"success"`
-	Errors     []cloudflare.ResponseInfo `json:"errors"`
+	accountAPITokenResponseStatus
+	Result     []accountAPIToken     `json:"result"`
+	ResultInfo cloudflare.ResultInfo `json:"result_info"`
+}
+
+// accountAPITokenResponse models the responses of POST /accounts/{account_id}/tokens and of GET
+// and PUT /accounts/{account_id}/tokens/{token_id}, whose result is the token.
+type accountAPITokenResponse struct {
+	accountAPITokenResponseStatus
+	Result cloudflare.APIToken `json:"result"`
+}
+
+// accountAPITokenValueResponse models PUT /accounts/{account_id}/tokens/{token_id}/value, whose
+// result is the token's new secret.
+type accountAPITokenValueResponse struct {
+	accountAPITokenResponseStatus
+	Result string `json:"result"`
+}
+
+// describeAPITokenScope renders an API token resource scope, such as "all zones" for
+// com.cloudflare.api.account.zone.* or "account <id>" for com.cloudflare.api.account.<id>.
+// Unrecognized scopes are kept as they are.
+func describeAPITokenScope(scope string) string {
+	key := strings.TrimPrefix(scope, apiTokenScopePrefix)
+	switch {
+	case key == "account.*":
+		return "all accounts"
+	case key == "account.zone.*":
+		return "all zones"
+	case key == "user.*":
+		return "all users"
+	case strings.HasPrefix(key, "account.zone."):
+		return "zone " + strings.TrimPrefix(key, "account.zone.")
+	case strings.HasPrefix(key, "account."):
+		return "account " + strings.TrimPrefix(key, "account.")
+	case strings.HasPrefix(key, "user."):
+		return "user " + strings.TrimPrefix(key, "user.")
+	default:
+		return scope
+	}
+}
+
+// apiTokenPolicyScopes describes the resources of a token policy. Resources are either a scope
+// mapped to "*", or an account scope mapped to the zone scopes it allows within the account.
+func apiTokenPolicyScopes(resources map[string]interface{}) []string {
+	keys := make([]string, 0, len(resources))
+	for key := range resources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var rv []string
+	for _, key := range keys {
+		nested, ok := resources[key].(map[string]interface{})
+		if !ok {
+			rv = append(rv, describeAPITokenScope(key))
+			continue
+		}
+		for _, inner := range apiTokenPolicyScopes(nested) {
+			rv = append(rv, fmt.Sprintf("%s in %s", inner, describeAPITokenScope(key)))
+		}
+	}
+
+	return rv
 }
 
-func apiTokenResource(token cloudflare.APIToken) (*v2.Resource, error) {
+// apiTokenHasWildcardScope reports whether any of the token's policies reaches every account,
+// zone or user through a "*" scope.
+func apiTokenHasWildcardScope(resources map[string]interface{}) bool {
+	for key, value := range resources {
+		if strings.HasSuffix(key, ".*") {
+			return true
+		}
+		if nested, ok := value.(map[string]interface{}); ok && apiTokenHasWildcardScope(nested) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func apiTokenPermissionGroupName(pg cloudflare.APITokenPermissionGroups) string {
+	if pg.Name != "" {
+		return pg.Name
+	}
+
+	return pg.ID
+}
+
+// apiTokenPolicyProfile describes what the token can do: each policy as
+// "<effect> <permission groups> on <scopes>", with the distinct permission groups and scopes
+// listed separately for filtering.
+func apiTokenPolicyProfile(token cloudflare.APIToken) map[string]interface{} {
+	policies := make([]interface{}, 0, len(token.Policies))
+	var permissionGroups, scopes []interface{}
+	seenPermissionGroups := make(map[string]bool)
+	seenScopes := make(map[string]bool)
+	wildcard := false
+	for _, policy := range token.Policies {
+		pgNames := make([]string, 0, len(policy.PermissionGroups))
+		for _, pg := range policy.PermissionGroups {
+			name := apiTokenPermissionGroupName(pg)
+			pgNames = append(pgNames, name)
+			if !seenPermissionGroups[name] {
+				seenPermissionGroups[name] = true
+				permissionGroups = append(permissionGroups, name)
+			}
+		}
+
+		policyScopes := apiTokenPolicyScopes(policy.Resources)
+		for _, scope := range policyScopes {
+			if !seenScopes[scope] {
+				seenScopes[scope] = true
+				scopes = append(scopes, scope)
+			}
+		}
+		wildcard = wildcard || apiTokenHasWildcardScope(policy.Resources)
+
+		policies = append(policies, fmt.Sprintf("%s %s on %s", policy.Effect, strings.Join(pgNames, ", "), strings.Join(policyScopes, ", ")))
+	}
+
+	return map[string]interface{}{
+		"policies":          policies,
+		"permission_groups": permissionGroups,
+		"resources":         scopes,
+		"wildcard_resource": wildcard,
+	}
+}
+
+// apiTokenResourceStatus maps a token status onto a resource status. Cloudflare documents
+// "active", "disabled" and "expired"; unrecognized values are reported as disabled rather than
+// silently enabled.
+func apiTokenResourceStatus(tokenStatus string) v2.Status_ResourceStatus {
+	if strings.EqualFold(tokenStatus, apiTokenStatusActive) {
+		return v2.Status_RESOURCE_STATUS_ENABLED
+	}
+
+	return v2.Status_RESOURCE_STATUS_DISABLED
+}
+
+// apiTokenIPConditionProfile lists the IP ranges the token may be used from and the ranges it may
+// not. A token with neither can be used from anywhere.
+func apiTokenIPConditionProfile(condition *cloudflare.APITokenCondition) map[string]interface{} {
+	allowed := []interface{}{}
+	denied := []interface{}{}
+	if condition != nil && condition.RequestIP != nil {
+		for _, ip := range condition.RequestIP.In {
+			allowed = append(allowed, ip)
+		}
+		for _, ip := range condition.RequestIP.NotIn {
+			denied = append(denied, ip)
+		}
+	}
+
+	return map[string]interface{}{
+		"request_ip_in":     allowed,
+		"request_ip_not_in": denied,
+		"ip_restricted":     len(allowed) > 0 || len(denied) > 0,
+	}
+}
+
+// apiTokenResource builds an account-owned API token. Token IDs are unique across accounts, but are
+// account-scoped like the account's other resources so that changes to a token reach its account.
+// The token authenticates as its account, which is recorded as the secret's identity.
+func apiTokenResource(token accountAPIToken, resourceID string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
 	secretTraitOpts := []rs.SecretTraitOption{
 		rs.WithSecretType(v2.SecretTrait_CREDENTIAL_TYPE_STATIC_SECRET),
 		rs.WithSecretDetail(apiTokenSecretDetail),
 	}
+	if parentResourceID != nil {
+		secretTraitOpts = append(secretTraitOpts, rs.WithSecretIdentityID(parentResourceID))
+	}
 	if token.ExpiresOn != nil {
 		secretTraitOpts = append(secretTraitOpts, rs.WithSecretExpiresAt(*token.ExpiresOn))
 	}
+	if token.LastUsedOn != nil {
+		secretTraitOpts = append(secretTraitOpts, rs.WithSecretLastUsedAt(*token.LastUsedOn))
+	}
+
+	profile := apiTokenPolicyProfile(token.APIToken)
+	for key, value := range apiTokenIPConditionProfile(token.Condition) {
+		profile[key] = value
+	}
+	profile["token_id"] = token.ID
+	profile["token_name"] = token.Name
+	profile["status"] = token.Status
 
-	resourceOpts := []rs.ResourceOption{}
+	resourceOpts := []rs.ResourceOption{
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithResourceProfile(profile),
+	}
+	if token.Status != "" {
+		resourceOpts = append(resourceOpts, rs.WithResourceStatus(apiTokenResourceStatus(token.Status), token.Status))
+	}
 	if token.IssuedOn != nil {
 		resourceOpts = append(resourceOpts, rs.WithResourceCreatedAt(*token.IssuedOn))
 	}
@@ -60,12 +309,13 @@ func apiTokenResource(token cloudflare.APIToken) (*v2.Resource, error) {
 		displayName = token.ID
 	}
 
-	return rs.NewSecretResource(displayName, resourceTypeAPIToken, token.ID, secretTraitOpts, resourceOpts...)
+	return rs.NewSecretResource(displayName, resourceTypeAPIToken, resourceID, secretTraitOpts, resourceOpts...)
 }
 
-func (o *apiTokenResourceType) List(ctx context.Context, _ *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
-	if o.accountId == "" {
-		return nil, nil, ErrMissingAccountID
+func (o *apiTokenResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
 	}
 
 	page, err := convertPageToken(opts.PageToken.Token)
@@ -73,14 +323,14 @@ func (o *apiTokenResourceType) List(ctx context.Context, _ *v2.ResourceId, opts
 		return nil, nil, fmt.Errorf("baton-cloudflare: invalid page token: %w", err)
 	}
 
-	resp, err := o.listAccountAPITokens(ctx, page, apiTokensPerPage)
+	resp, err := o.listAccountAPITokens(ctx, accountID, page, apiTokensPerPage)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	rv := make([]*v2.Resource, 0, len(resp.Result))
 	for _, token := range resp.Result {
-		tokenResource, err := apiTokenResource(token)
+		tokenResource, err := apiTokenResource(token, accountScopedID(o.accountId, accountID, token.ID), parentResourceID)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -100,19 +350,369 @@ func (o *apiTokenResourceType) Grants(_ context.Context, _ *v2.Resource, _ rs.Sy
 	return nil, nil, nil
 }
 
+// Rotate rolls the token's secret and returns the new one. The SDK encrypts the returned value
+// with the caller's credential options before it leaves the connector.
+func (o *apiTokenResourceType) Rotate(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	_ *v2.LocalCredentialOptions,
+) ([]*v2.PlaintextData, annotations.Annotations, error) {
+	if resourceId.ResourceType != resourceTypeAPIToken.Id {
+		return nil, nil, fmt.Errorf("baton-cloudflare: invalid resource type for rotate: %s", resourceId.ResourceType)
+	}
+
+	accountID, tokenID := parseAccountScopedID(o.accountId, resourceId.Resource)
+	value, err := o.rollAccountAPIToken(ctx, accountID, tokenID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rv := []*v2.PlaintextData{
+		{
+			Name:        "token",
+			Description: fmt.Sprintf("Value of Cloudflare API token %s", tokenID),
+			Bytes:       []byte(value),
+		},
+	}
+
+	return rv, nil, nil
+}
+
+func (o *apiTokenResourceType) RotateCapabilityDetails(_ context.Context) (*v2.CredentialDetailsCredentialRotation, annotations.Annotations, error) {
+	return &v2.CredentialDetailsCredentialRotation{
+		SupportedCredentialOptions: []v2.CapabilityDetailCredentialOption{
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_TOKEN,
+		},
+		PreferredCredentialOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_TOKEN,
+	}, nil, nil
+}
+
+// The fields a new token is created from. Issuance requests name them in their scopes.
+const (
+	apiTokenFieldName               = "name"
+	apiTokenFieldPermissionGroupIDs = "permission_group_ids"
+	apiTokenFieldResources          = "resources"
+	apiTokenFieldRequestIPIn        = "request_ip_in"
+	apiTokenFieldRequestIPNotIn     = "request_ip_not_in"
+)
+
+var apiTokenFields = []string{
+	apiTokenFieldName,
+	apiTokenFieldPermissionGroupIDs,
+	apiTokenFieldResources,
+	apiTokenFieldRequestIPIn,
+	apiTokenFieldRequestIPNotIn,
+}
+
+// apiTokenCreateRequest is what a new token is created from: a name, its permission groups, the
+// resources they apply to, an optional expiry, and optional IP allow and deny lists.
+type apiTokenCreateRequest struct {
+	Name               string
+	PermissionGroupIDs []string
+	Resources          []string
+	ExpiresOn          *time.Time
+	RequestIPIn        []string
+	RequestIPNotIn     []string
+}
+
+// parseAPITokenCreateRequest builds a token request from its fields, keyed by apiTokenFields, and
+// its expiry. A name and at least one permission group are required, and the expiry, when given,
+// must be in the future.
+func parseAPITokenCreateRequest(fields map[string][]string, expiresOn *time.Time, now time.Time) (*apiTokenCreateRequest, error) {
+	names := fields[apiTokenFieldName]
+	if len(names) > 1 {
+		return nil, fmt.Errorf("baton-cloudflare: an API token takes a single name, got %d", len(names))
+	}
+
+	req := &apiTokenCreateRequest{
+		PermissionGroupIDs: fields[apiTokenFieldPermissionGroupIDs],
+		Resources:          fields[apiTokenFieldResources],
+		RequestIPIn:        fields[apiTokenFieldRequestIPIn],
+		RequestIPNotIn:     fields[apiTokenFieldRequestIPNotIn],
+	}
+	if len(names) == 1 {
+		req.Name = strings.TrimSpace(names[0])
+	}
+	if req.Name == "" {
+		return nil, fmt.Errorf("baton-cloudflare: an API token name is required")
+	}
+	if len(req.PermissionGroupIDs) == 0 {
+		return nil, fmt.Errorf("baton-cloudflare: at least one permission group ID is required")
+	}
+
+	if expiresOn != nil {
+		if !expiresOn.After(now) {
+			return nil, fmt.Errorf("baton-cloudflare: expiry %s is in the past", expiresOn.Format(time.RFC3339))
+		}
+		t := expiresOn.UTC()
+		req.ExpiresOn = &t
+	}
+
+	return req, nil
+}
+
+// newAPIToken builds the token to create: a single allow policy granting the permission groups on
+// the requested resources, or on the whole account when none are given. Permission group IDs are
+// resolved against the permission groups Cloudflare lists, which also supplies their names.
+func newAPIToken(req *apiTokenCreateRequest, accountID string, permissionGroups []cloudflare.APITokenPermissionGroups) (cloudflare.APIToken, error) {
+	byID := make(map[string]cloudflare.APITokenPermissionGroups, len(permissionGroups))
+	for _, pg := range permissionGroups {
+		byID[pg.ID] = pg
+	}
+
+	pgs := make([]cloudflare.APITokenPermissionGroups, 0, len(req.PermissionGroupIDs))
+	for _, id := range req.PermissionGroupIDs {
+		pg, ok := byID[id]
+		if !ok {
+			return cloudflare.APIToken{}, fmt.Errorf("baton-cloudflare: unknown API token permission group ID %s", id)
+		}
+		pgs = append(pgs, cloudflare.APITokenPermissionGroups{ID: pg.ID, Name: pg.Name})
+	}
+
+	scopes := req.Resources
+	if len(scopes) == 0 {
+		scopes = []string{apiTokenScopePrefix + "account." + accountID}
+	}
+	resources := make(map[string]interface{}, len(scopes))
+	for _, scope := range scopes {
+		resources[scope] = "*"
+	}
+
+	token := cloudflare.APIToken{
+		Name:      req.Name,
+		ExpiresOn: req.ExpiresOn,
+		Policies: []cloudflare.APITokenPolicies{
+			{
+				Effect:           policyAccessAllow,
+				Resources:        resources,
+				PermissionGroups: pgs,
+			},
+		},
+	}
+	if len(req.RequestIPIn) > 0 || len(req.RequestIPNotIn) > 0 {
+		token.Condition = &cloudflare.APITokenCondition{
+			RequestIP: &cloudflare.APITokenRequestIPCondition{In: req.RequestIPIn, NotIn: req.RequestIPNotIn},
+		}
+	}
+
+	return token, nil
+}
+
+// createToken creates the requested token in the account and returns it with its secret.
+func (o *apiTokenResourceType) createToken(ctx context.Context, accountID string, req *apiTokenCreateRequest) (cloudflare.APIToken, error) {
+	permissionGroups, err := o.client.ListAPITokensPermissionGroups(ctx)
+	if err != nil {
+		return cloudflare.APIToken{}, fmt.Errorf("baton-cloudflare: failed to list API token permission groups: %w", err)
+	}
+	token, err := newAPIToken(req, accountID, permissionGroups)
+	if err != nil {
+		return cloudflare.APIToken{}, err
+	}
+
+	return o.createAccountAPIToken(ctx, accountID, token)
+}
+
+// Delete deletes the token, which stops it from working immediately. Tokens that are already gone
+// count as deleted.
+func (o *apiTokenResourceType) Delete(ctx context.Context, resourceId *v2.ResourceId, _ *v2.ResourceId) (annotations.Annotations, error) {
+	if resourceId.ResourceType != resourceTypeAPIToken.Id {
+		return nil, fmt.Errorf("baton-cloudflare: invalid resource type for delete: %s", resourceId.ResourceType)
+	}
+
+	accountID, tokenID := parseAccountScopedID(o.accountId, resourceId.Resource)
+	if err := o.deleteAccountAPIToken(ctx, accountID, tokenID); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (o *apiTokenResourceType) ResourceActions(ctx context.Context, registry actions.ActionRegistry) error {
+	return registry.Register(ctx, disableTokenSchema, o.disableToken)
+}
+
+// disableToken is the disable_token action. Cloudflare only updates a token as a whole, so the
+// token is read and written back with its status set to disabled; its policies are unchanged.
+func (o *apiTokenResourceType) disableToken(ctx context.Context, args *structpb.Struct) (*structpb.Struct, annotations.Annotations, error) {
+	resourceID, err := actions.RequireResourceIDArg(args, actionResourceIDArg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: %w", err)
+	}
+	if resourceID.ResourceType != resourceTypeAPIToken.Id {
+		return nil, nil, fmt.Errorf("baton-cloudflare: invalid resource type for %s: %s", disableTokenAction, resourceID.ResourceType)
+	}
+
+	accountID, tokenID := parseAccountScopedID(o.accountId, resourceID.Resource)
+	token, err := o.getAccountAPIToken(ctx, accountID, tokenID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if token.Status != apiTokenStatusDisabled {
+		token, err = o.updateAccountAPIToken(ctx, accountID, tokenID, cloudflare.APIToken{
+			Name:      token.Name,
+			Status:    apiTokenStatusDisabled,
+			NotBefore: token.NotBefore,
+			ExpiresOn: token.ExpiresOn,
+			Policies:  token.Policies,
+			Condition: token.Condition,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	rv := &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			"success": structpb.NewBoolValue(true),
+			"status":  structpb.NewStringValue(token.Status),
+		},
+	}
+
+	return rv, nil, nil
+}
+
+// accountAPITokensSnapshot returns every API token owned by the account. During a sync the
+// tokens are listed once and kept in the session store; without one they are listed directly.
+func (o *apiTokenResourceType) accountAPITokensSnapshot(ctx context.Context, ss sessions.SessionStore, accountID string) ([]accountAPIToken, error) {
+	key := fmt.Sprintf("%s:%s", apiTokenSnapshotKeyPrefix, accountID)
+	if ss != nil {
+		tokens, found, err := session.GetJSON[[]accountAPIToken](ctx, ss, key)
+		if err != nil {
+			return nil, fmt.Errorf("baton-cloudflare: failed to read account API token snapshot: %w", err)
+		}
+		if found {
+			return tokens, nil
+		}
+	}
+
+	var tokens []accountAPIToken
+	for page := 1; ; page++ {
+		resp, err := o.listAccountAPITokens(ctx, accountID, page, apiTokensPerPage)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, resp.Result...)
+		if len(resp.Result) < apiTokensPerPage {
+			break
+		}
+	}
+
+	if ss != nil {
+		if err := session.SetJSON(ctx, ss, key, tokens); err != nil {
+			return nil, fmt.Errorf("baton-cloudflare: failed to store account API token snapshot: %w", err)
+		}
+	}
+
+	return tokens, nil
+}
+
 // listAccountAPITokens calls GET /accounts/{account_id}/tokens. cloudflare-go's
 // APITokens helper only covers /user/tokens, so account-owned tokens are fetched
 // directly, reusing the same auth headers the rest of the connector relies on.
-func (o *apiTokenResourceType) listAccountAPITokens(ctx context.Context, page, perPage int) (*accountAPITokenListResponse, error) {
-	if o.httpClient == nil {
-		httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
-		if err != nil {
-			return nil, fmt.Errorf("baton-cloudflare: failed to create http client: %w", err)
-		}
-		o.httpClient = uhttp.NewBaseHttpClient(httpClient)
+func (o *apiTokenResourceType) listAccountAPITokens(ctx context.Context, accountID string, page, perPage int) (*accountAPITokenListResponse, error) {
+	uri, err := o.accountAPITokensURL(accountID)
+	if err != nil {
+		return nil, err
+	}
+	q := uri.Query()
+	q.Set("page", strconv.Itoa(page))
+	q.Set("per_page", strconv.Itoa(perPage))
+	uri.RawQuery = q.Encode()
+
+	var result accountAPITokenListResponse
+	if err := o.doAccountAPITokenRequest(ctx, http.MethodGet, uri, nil, &result, "list account API tokens"); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// rollAccountAPIToken calls PUT /accounts/{account_id}/tokens/{token_id}/value, which replaces the
+// token's secret and returns the new one. The previous secret stops working immediately.
+func (o *apiTokenResourceType) rollAccountAPIToken(ctx context.Context, accountID, tokenID string) (string, error) {
+	uri, err := o.accountAPITokensURL(accountID, tokenID, "value")
+	if err != nil {
+		return "", err
+	}
+
+	var result accountAPITokenValueResponse
+	if err := o.doAccountAPITokenRequest(ctx, http.MethodPut, uri, struct{}{}, &result, "roll account API token"); err != nil {
+		return "", err
 	}
 
-	endpointURL, err := url.JoinPath(o.client.BaseURL, "accounts", o.accountId, "tokens")
+	return result.Result, nil
+}
+
+// createAccountAPIToken calls POST /accounts/{account_id}/tokens. The created token is the only
+// response that includes its secret.
+func (o *apiTokenResourceType) createAccountAPIToken(ctx context.Context, accountID string, token cloudflare.APIToken) (cloudflare.APIToken, error) {
+	uri, err := o.accountAPITokensURL(accountID)
+	if err != nil {
+		return cloudflare.APIToken{}, err
+	}
+
+	var result accountAPITokenResponse
+	if err := o.doAccountAPITokenRequest(ctx, http.MethodPost, uri, token, &result, "create account API token"); err != nil {
+		return cloudflare.APIToken{}, err
+	}
+
+	return result.Result, nil
+}
+
+// getAccountAPIToken calls GET /accounts/{account_id}/tokens/{token_id}.
+func (o *apiTokenResourceType) getAccountAPIToken(ctx context.Context, accountID, tokenID string) (cloudflare.APIToken, error) {
+	uri, err := o.accountAPITokensURL(accountID, tokenID)
+	if err != nil {
+		return cloudflare.APIToken{}, err
+	}
+
+	var result accountAPITokenResponse
+	if err := o.doAccountAPITokenRequest(ctx, http.MethodGet, uri, nil, &result, "get account API token"); err != nil {
+		return cloudflare.APIToken{}, err
+	}
+
+	return result.Result, nil
+}
+
+// updateAccountAPIToken calls PUT /accounts/{account_id}/tokens/{token_id}, which replaces the
+// token's name, status, validity, policies and conditions, and returns the updated token.
+func (o *apiTokenResourceType) updateAccountAPIToken(ctx context.Context, accountID, tokenID string, token cloudflare.APIToken) (cloudflare.APIToken, error) {
+	uri, err := o.accountAPITokensURL(accountID, tokenID)
+	if err != nil {
+		return cloudflare.APIToken{}, err
+	}
+
+	var result accountAPITokenResponse
+	if err := o.doAccountAPITokenRequest(ctx, http.MethodPut, uri, token, &result, "update account API token"); err != nil {
+		return cloudflare.APIToken{}, err
+	}
+
+	return result.Result, nil
+}
+
+// deleteAccountAPIToken calls DELETE /accounts/{account_id}/tokens/{token_id}.
+func (o *apiTokenResourceType) deleteAccountAPIToken(ctx context.Context, accountID, tokenID string) error {
+	uri, err := o.accountAPITokensURL(accountID, tokenID)
+	if err != nil {
+		return err
+	}
+
+	var result accountAPITokenResponseStatus
+
+	return o.doAccountAPITokenRequest(ctx, http.MethodDelete, uri, nil, &result, "delete account API token")
+}
+
+// accountAPITokensURL builds the URL of /accounts/{account_id}/tokens, followed by elem.
+func (o *apiTokenResourceType) accountAPITokensURL(accountID string, elem ...string) (*url.URL, error) {
+	if accountID == "" {
+		return nil, ErrMissingAccountID
+	}
+
+	endpointURL, err := url.JoinPath(o.client.BaseURL, append([]string{"accounts", accountID, "tokens"}, elem...)...)
 	if err != nil {
 		return nil, fmt.Errorf("baton-cloudflare: failed to build endpoint url: %w", err)
 	}
@@ -120,14 +720,28 @@ func (o *apiTokenResourceType) listAccountAPITokens(ctx context.Context, page, p
 	if err != nil {
 		return nil, fmt.Errorf("baton-cloudflare: failed to parse endpoint url: %w", err)
 	}
-	q := uri.Query()
-	q.Set("page", strconv.Itoa(page))
-	q.Set("per_page", strconv.Itoa(perPage))
-	uri.RawQuery = q.Encode()
+
+	return uri, nil
+}
+
+// doAccountAPITokenRequest sends a request to the account tokens endpoints with the connector's
+// credentials and decodes the response into result. A nil body sends no request body. The
+// operation names the request in errors.
+func (o *apiTokenResourceType) doAccountAPITokenRequest(ctx context.Context, method string, uri *url.URL, body interface{}, result accountAPITokenResult, operation string) error {
+	if o.httpClient == nil {
+		httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
+		if err != nil {
+			return fmt.Errorf("baton-cloudflare: failed to create http client: %w", err)
+		}
+		o.httpClient = uhttp.NewBaseHttpClient(httpClient)
+	}
 
 	reqOpts := []uhttp.RequestOption{
 		uhttp.WithAcceptJSONHeader(),
 	}
+	if body != nil {
+		reqOpts = append(reqOpts, uhttp.WithJSONBody(body))
+	}
 	if o.client.APIToken != "" {
 		reqOpts = append(reqOpts, uhttp.WithBearerToken(o.client.APIToken))
 	}
@@ -138,26 +752,18 @@ func (o *apiTokenResourceType) listAccountAPITokens(ctx context.Context, page, p
 		reqOpts = append(reqOpts, uhttp.WithHeader(XAuthKeyHeaderKey, o.client.APIKey))
 	}
 
-	req, err := o.httpClient.NewRequest(ctx, http.MethodGet, uri, reqOpts...)
+	req, err := o.httpClient.NewRequest(ctx, method, uri, reqOpts...)
 	if err != nil {
-		return nil, fmt.Errorf("baton-cloudflare: failed to create request: %w", err)
+		return fmt.Errorf("baton-cloudflare: failed to create request: %w", err)
 	}
 
-	var result accountAPITokenListResponse
-	resp, err := o.httpClient.Do(req, uhttp.WithJSONResponse(&result))
+	resp, err := o.httpClient.Do(req, uhttp.WithJSONResponse(result))
 	if err != nil {
-		return nil, fmt.Errorf("baton-cloudflare: failed to list account API tokens: %w", err)
+		return fmt.Errorf("baton-cloudflare: failed to %s: %w", operation, err)
 	}
 	defer resp.Body.Close()
 
-	if !result.Success {
-		if len(result.Errors) > 0 {
-			return nil, fmt.Errorf("baton-cloudflare: list account API tokens failed: %s (code %d)", result.Errors[0].Message, result.Errors[0].Code)
-		}
-		return nil, fmt.Errorf("baton-cloudflare: list account API tokens failed: unknown error")
-	}
-
-	return &result, nil
+	return result.responseError(operation)
 }
 
 func apiTokenBuilder(client *cloudflare.API, accountId, emailId string) *apiTokenResourceType {
@@ -0,0 +1,141 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+)
+
+// issuedAPITokenNamePrefix starts the default name of an API token issued as an account credential;
+// the request ID completes it, so each issued token can be traced back to its request.
+const issuedAPITokenNamePrefix = "baton-cloudflare"
+
+// issuedAPITokenFieldSeparator separates a field from its value in an issuance scope, as in
+// name=deploy. Permission group IDs, resource scopes and IP ranges never contain it.
+const issuedAPITokenFieldSeparator = "="
+
+// IssueCapabilityDetails advertises account API tokens as the credentials issued for an account.
+// The token's scopes are its permission group IDs and its other fields; see issuedAPITokenFields.
+func (o *accountResourceType) IssueCapabilityDetails(_ context.Context) (*v2.CredentialDetailsCredentialIssue, annotations.Annotations, error) {
+	return &v2.CredentialDetailsCredentialIssue{
+		Options: []*v2.CredentialIssueOptionDescriptor{
+			{
+				Option:               v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_TOKEN,
+				CustomScopesAllowed:  true,
+				Expiry:               &v2.IssuanceExpiryCapability{},
+				ResourceMode:         v2.CredentialResourceMode_CREDENTIAL_RESOURCE_MODE_DISCOVERABLE,
+				SecretResourceTypeId: resourceTypeAPIToken.Id,
+			},
+		},
+		PreferredOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_TOKEN,
+	}, nil, nil
+}
+
+// Issue creates an account API token and returns its secret, which Cloudflare only sends when the
+// token is created. The SDK encrypts the secret with the request's encryption configs before it
+// leaves the connector. The token is synced as an API token resource like any other.
+func (o *accountResourceType) Issue(ctx context.Context, input *connectorbuilder.CredentialIssueInput) (*connectorbuilder.CredentialIssueOutput, error) {
+	if input.IdentityID.GetResourceType() != resourceTypeAccount.Id {
+		return nil, fmt.Errorf("baton-cloudflare: invalid resource type for issue: %s", input.IdentityID.GetResourceType())
+	}
+	accountID := input.IdentityID.GetResource()
+	if accountID == "" {
+		return nil, ErrMissingAccountID
+	}
+
+	req, err := newIssuedAPITokenRequest(input, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := o.apiTokens.createToken(ctx, accountID, req)
+	if err != nil {
+		return nil, err
+	}
+	value := created.Value
+	if value == "" {
+		return nil, fmt.Errorf("baton-cloudflare: cloudflare returned no secret for API token %s", created.ID)
+	}
+	created.Value = ""
+
+	secret, err := apiTokenResource(accountAPIToken{APIToken: created}, accountScopedID(o.accountId, accountID, created.ID), accountResourceID(accountID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &connectorbuilder.CredentialIssueOutput{
+		Secret: secret,
+		PlaintextData: []*v2.PlaintextData{
+			{
+				Name:        "token",
+				Description: fmt.Sprintf("Value of Cloudflare API token %s", created.ID),
+				Bytes:       []byte(value),
+			},
+		},
+		ResourceMode: v2.CredentialResourceMode_CREDENTIAL_RESOURCE_MODE_DISCOVERABLE,
+	}, nil
+}
+
+// newIssuedAPITokenRequest builds the token to create from an issuance request. The token is named
+// after the request unless a name is given, and the requested expiry, rounded down to the second
+// Cloudflare keeps, is its expiry.
+func newIssuedAPITokenRequest(input *connectorbuilder.CredentialIssueInput, now time.Time) (*apiTokenCreateRequest, error) {
+	fields, err := issuedAPITokenFields(input.CredentialOptions.GetToken())
+	if err != nil {
+		return nil, err
+	}
+	if len(fields[apiTokenFieldName]) == 0 {
+		fields[apiTokenFieldName] = []string{fmt.Sprintf("%s %s", issuedAPITokenNamePrefix, input.RequestID)}
+	}
+
+	var expiresOn *time.Time
+	if input.ExpiresAt != nil {
+		t := input.ExpiresAt.AsTime().Truncate(time.Second)
+		expiresOn = &t
+	}
+
+	return parseAPITokenCreateRequest(fields, expiresOn, now)
+}
+
+// issuedAPITokenFields reads the token's fields from the issuance options. Each scope is either a
+// permission group ID or a field and its value, such as name=deploy,
+// resources=com.cloudflare.api.account.zone.<zone ID>, request_ip_in=192.0.2.0/24 or
+// request_ip_not_in=198.51.100.1/32; fields other than the name may repeat. Audiences are resources
+// the token applies to. Without resources, the token applies to the whole account.
+func issuedAPITokenFields(token *v2.CredentialIssueOptions_Token) (map[string][]string, error) {
+	fields := make(map[string][]string)
+	for _, scope := range token.GetScopes() {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+
+		field, value, ok := strings.Cut(scope, issuedAPITokenFieldSeparator)
+		if !ok {
+			fields[apiTokenFieldPermissionGroupIDs] = append(fields[apiTokenFieldPermissionGroupIDs], scope)
+			continue
+		}
+		field, value = strings.TrimSpace(field), strings.TrimSpace(value)
+		if !slices.Contains(apiTokenFields, field) {
+			return nil, fmt.Errorf("baton-cloudflare: unknown API token field %q in scope %q", field, scope)
+		}
+		if value == "" {
+			return nil, fmt.Errorf("baton-cloudflare: API token field %q has no value", field)
+		}
+		fields[field] = append(fields[field], value)
+	}
+
+	for _, audience := range token.GetAudiences() {
+		if audience = strings.TrimSpace(audience); audience != "" {
+			fields[apiTokenFieldResources] = append(fields[apiTokenFieldResources], audience)
+		}
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,133 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The secret Cloudflare returns from token creation comes back as plaintext data for the SDK to
+// encrypt, and the secret resource records the account as its identity.
+func TestAccountIssueAPIToken(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 600, time.UTC)
+	var created cloudflare.APIToken
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/user/tokens/permission_groups":
+			_, _ = w.Write([]byte(`{"success":true,"result":[{"id":"pg-dns","name":"DNS Write"}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/accounts/acct-1/tokens":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			created.ID = "token-1"
+			created.Status = apiTokenStatusActive
+			created.Value = "s3cr3t-token-value"
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "result": created})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := cloudflare.NewWithAPIToken("test-token", cloudflare.BaseURL(server.URL))
+	require.NoError(t, err)
+	o := accountBuilder(client, "acct-1", "", nil, nil)
+
+	identityID := accountResourceID("acct-1")
+	output, err := o.Issue(context.Background(), &connectorbuilder.CredentialIssueInput{
+		IdentityID: identityID,
+		CredentialOptions: v2.CredentialIssueOptions_builder{
+			Token: v2.CredentialIssueOptions_Token_builder{
+				Scopes:    []string{"pg-dns", "name=deploy", "request_ip_in=192.0.2.0/24"},
+				Audiences: []string{"com.cloudflare.api.account.zone.zone-1"},
+			}.Build(),
+		}.Build(),
+		ExpiresAt: timestamppb.New(expiresAt),
+		RequestID: "req-1",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, output.PlaintextData, 1)
+	assert.Equal(t, "token", output.PlaintextData[0].GetName())
+	assert.Equal(t, []byte("s3cr3t-token-value"), output.PlaintextData[0].GetBytes())
+	assert.Equal(t, v2.CredentialResourceMode_CREDENTIAL_RESOURCE_MODE_DISCOVERABLE, output.ResourceMode)
+
+	assert.Equal(t, "deploy", created.Name)
+	require.Len(t, created.Policies, 1)
+	assert.Equal(t, "pg-dns", created.Policies[0].PermissionGroups[0].ID)
+	assert.Equal(t, map[string]interface{}{"com.cloudflare.api.account.zone.zone-1": "*"}, created.Policies[0].Resources)
+	require.NotNil(t, created.Condition)
+	assert.Equal(t, []string{"192.0.2.0/24"}, created.Condition.RequestIP.In)
+	require.NotNil(t, created.ExpiresOn)
+	assert.Equal(t, expiresAt.Truncate(time.Second), *created.ExpiresOn)
+
+	secret := output.Secret
+	assert.Equal(t, resourceTypeAPIToken.Id, secret.GetId().GetResourceType())
+	assert.Equal(t, "token-1", secret.GetId().GetResource())
+	assert.NotContains(t, secret.GetProfile().String(), "s3cr3t-token-value")
+
+	trait := &v2.SecretTrait{}
+	secretAnnos := annotations.Annotations(secret.GetAnnotations())
+	found, err := secretAnnos.Pick(trait)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.True(t, proto.Equal(identityID, trait.GetIdentityId()))
+}
+
+// Without a name, an issued token is named after its request and applies to the whole account.
+func TestNewIssuedAPITokenRequestDefaults(t *testing.T) {
+	req, err := newIssuedAPITokenRequest(&connectorbuilder.CredentialIssueInput{
+		IdentityID: accountResourceID("acct-1"),
+		CredentialOptions: v2.CredentialIssueOptions_builder{
+			Token: v2.CredentialIssueOptions_Token_builder{Scopes: []string{"pg-dns"}}.Build(),
+		}.Build(),
+		RequestID: "req-1",
+	}, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "baton-cloudflare req-1", req.Name)
+	assert.Equal(t, []string{"pg-dns"}, req.PermissionGroupIDs)
+	assert.Empty(t, req.Resources)
+	assert.Nil(t, req.ExpiresOn)
+}
+
+func TestNewIssuedAPITokenRequestRequiresScopes(t *testing.T) {
+	_, err := newIssuedAPITokenRequest(&connectorbuilder.CredentialIssueInput{
+		IdentityID: accountResourceID("acct-1"),
+		CredentialOptions: v2.CredentialIssueOptions_builder{
+			Token: v2.CredentialIssueOptions_Token_builder{}.Build(),
+		}.Build(),
+		RequestID: "req-1",
+	}, time.Now())
+	assert.ErrorContains(t, err, "at least one permission group ID")
+}
+
+func TestIssuedAPITokenFieldsRejectsUnknownFields(t *testing.T) {
+	_, err := issuedAPITokenFields(v2.CredentialIssueOptions_Token_builder{
+		Scopes: []string{"pg-dns", "ttl=3600"},
+	}.Build())
+	assert.ErrorContains(t, err, "unknown API token field")
+
+	_, err = issuedAPITokenFields(v2.CredentialIssueOptions_Token_builder{
+		Scopes: []string{"pg-dns", "name="},
+	}.Build())
+	assert.ErrorContains(t, err, "has no value")
+}
+
+// API tokens are only created through issuance, which returns their secret; creating one from a
+// resource would throw the secret away.
+func TestAPITokenNotResourceCreator(t *testing.T) {
+	var builder interface{} = apiTokenBuilder(nil, "acct-1", "")
+	_, ok := builder.(connectorbuilder.ResourceCreator)
+	assert.False(t, ok)
+}
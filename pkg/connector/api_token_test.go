@@ -1,11 +1,14 @@
 package connector
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/cloudflare/cloudflare-go"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/actions"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,7 +25,7 @@ func TestAPITokenResource(t *testing.T) {
 		ExpiresOn: &expires,
 	}
 
-	resource, err := apiTokenResource(token)
+	resource, err := apiTokenResource(accountAPIToken{APIToken: token}, token.ID, nil)
 	require.NoError(t, err)
 	assert.Equal(t, token.ID, resource.GetId().GetResource())
 	assert.Equal(t, resourceTypeAPIToken.GetId(), resource.GetId().GetResourceType())
@@ -45,7 +48,153 @@ func TestAPITokenResource(t *testing.T) {
 func TestAPITokenResourceFallbackDisplayName(t *testing.T) {
 	token := cloudflare.APIToken{ID: "abc123", Status: "active"}
 
-	resource, err := apiTokenResource(token)
+	resource, err := apiTokenResource(accountAPIToken{APIToken: token}, token.ID, nil)
 	require.NoError(t, err)
 	assert.Equal(t, token.ID, resource.GetDisplayName())
 }
+
+func TestAccountAPITokenValueResponse(t *testing.T) {
+	var resp accountAPITokenValueResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"success": true, "errors": [], "result": "new-secret"}`), &resp))
+	require.NoError(t, resp.responseError("roll account API token"))
+	assert.Equal(t, "new-secret", resp.Result)
+
+	var failed accountAPITokenValueResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"success": false, "errors": [{"code": 9109, "message": "Unauthorized"}]}`), &failed))
+	assert.EqualError(t, failed.responseError("roll account API token"), "baton-cloudflare: roll account API token failed: Unauthorized (code 9109)")
+}
+
+func TestAPITokenRotate(t *testing.T) {
+	tokens := apiTokenBuilder(nil, "acct-1", "")
+
+	details, _, err := tokens.RotateCapabilityDetails(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_TOKEN, details.GetPreferredCredentialOption())
+
+	_, _, err = tokens.Rotate(context.Background(), &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: "token-1"}, nil)
+	assert.Error(t, err)
+}
+
+func TestDisableTokenActionSchema(t *testing.T) {
+	ctx := context.Background()
+	manager := actions.NewActionManager(ctx)
+	registry, err := manager.GetTypeRegistry(ctx, resourceTypeAPIToken.Id)
+	require.NoError(t, err)
+	require.NoError(t, apiTokenBuilder(nil, "acct-1", "").ResourceActions(ctx, registry))
+
+	schemas, _, err := manager.ListActionSchemas(ctx, resourceTypeAPIToken.Id)
+	require.NoError(t, err)
+	require.Len(t, schemas, 1)
+	assert.Equal(t, disableTokenAction, schemas[0].GetName())
+	assert.Equal(t, resourceTypeAPIToken.GetId(), schemas[0].GetResourceTypeId())
+}
+
+func TestAPITokenDeleteInvalidResourceType(t *testing.T) {
+	_, err := apiTokenBuilder(nil, "acct-1", "").Delete(context.Background(), &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: "token-1"}, nil)
+	assert.Error(t, err)
+}
+
+func TestParseAPITokenCreateRequest(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiresOn := now.AddDate(0, 0, 7)
+	req, err := parseAPITokenCreateRequest(map[string][]string{
+		apiTokenFieldName:               {" deploy "},
+		apiTokenFieldPermissionGroupIDs: {"pg-dns-edit", "pg-zone-read"},
+		apiTokenFieldResources:          {"com.cloudflare.api.account.zone.zone-1", "com.cloudflare.api.account.zone.zone-2"},
+		apiTokenFieldRequestIPIn:        {"192.0.2.0/24"},
+	}, &expiresOn, now)
+	require.NoError(t, err)
+	assert.Equal(t, "deploy", req.Name)
+	assert.Equal(t, []string{"pg-dns-edit", "pg-zone-read"}, req.PermissionGroupIDs)
+	assert.Equal(t, []string{"com.cloudflare.api.account.zone.zone-1", "com.cloudflare.api.account.zone.zone-2"}, req.Resources)
+	assert.Equal(t, []string{"192.0.2.0/24"}, req.RequestIPIn)
+	require.NotNil(t, req.ExpiresOn)
+	assert.Equal(t, expiresOn, *req.ExpiresOn)
+
+	expired := now.AddDate(0, 0, -1)
+	_, err = parseAPITokenCreateRequest(map[string][]string{
+		apiTokenFieldName:               {"deploy"},
+		apiTokenFieldPermissionGroupIDs: {"pg-dns-edit"},
+	}, &expired, now)
+	assert.ErrorContains(t, err, "in the past")
+
+	_, err = parseAPITokenCreateRequest(map[string][]string{apiTokenFieldName: {"deploy"}}, nil, now)
+	assert.ErrorContains(t, err, "at least one permission group ID")
+
+	_, err = parseAPITokenCreateRequest(map[string][]string{
+		apiTokenFieldName:               {"deploy", "ci"},
+		apiTokenFieldPermissionGroupIDs: {"pg-dns-edit"},
+	}, nil, now)
+	assert.ErrorContains(t, err, "single name")
+}
+
+func TestNewAPIToken(t *testing.T) {
+	permissionGroups := []cloudflare.APITokenPermissionGroups{
+		{ID: "pg-dns-edit", Name: "DNS Write", Scopes: []string{"com.cloudflare.api.account.zone"}},
+		{ID: "pg-zone-read", Name: "Zone Read"},
+	}
+
+	token, err := newAPIToken(&apiTokenCreateRequest{
+		Name:               "deploy",
+		PermissionGroupIDs: []string{"pg-dns-edit"},
+		RequestIPNotIn:     []string{"198.51.100.1/32"},
+	}, "acct-1", permissionGroups)
+	require.NoError(t, err)
+	require.Len(t, token.Policies, 1)
+	assert.Equal(t, policyAccessAllow, token.Policies[0].Effect)
+	assert.Equal(t, map[string]interface{}{"com.cloudflare.api.account.acct-1": "*"}, token.Policies[0].Resources)
+	assert.Equal(t, []cloudflare.APITokenPermissionGroups{{ID: "pg-dns-edit", Name: "DNS Write"}}, token.Policies[0].PermissionGroups)
+	require.NotNil(t, token.Condition)
+	assert.Equal(t, []string{"198.51.100.1/32"}, token.Condition.RequestIP.NotIn)
+
+	_, err = newAPIToken(&apiTokenCreateRequest{Name: "deploy", PermissionGroupIDs: []string{"pg-unknown"}}, "acct-1", permissionGroups)
+	assert.Error(t, err)
+}
+
+func TestAPITokenResourceStatusAndConditions(t *testing.T) {
+	var resp accountAPITokenListResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"success": true,
+		"result": [
+			{
+				"id": "token-1",
+				"name": "ci",
+				"status": "disabled",
+				"last_used_on": "2025-06-01T12:00:00Z",
+				"condition": {"request.ip": {"in": ["192.0.2.0/24"], "not_in": ["192.0.2.1/32"]}}
+			},
+			{"id": "token-2", "name": "legacy", "status": "active"}
+		]
+	}`), &resp))
+	require.Len(t, resp.Result, 2)
+
+	disabled, err := apiTokenResource(resp.Result[0], "token-1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, v2.Status_RESOURCE_STATUS_DISABLED, disabled.GetStatus().GetStatus())
+	assert.Equal(t, "disabled", disabled.GetStatus().GetDetails())
+
+	secretTrait := &v2.SecretTrait{}
+	annos := annotations.Annotations(disabled.GetAnnotations())
+	ok, err := annos.Pick(secretTrait)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC), secretTrait.GetLastUsedAt().AsTime())
+
+	profile := disabled.GetProfile().AsMap()
+	assert.Equal(t, []interface{}{"192.0.2.0/24"}, profile["request_ip_in"])
+	assert.Equal(t, []interface{}{"192.0.2.1/32"}, profile["request_ip_not_in"])
+	assert.Equal(t, true, profile["ip_restricted"])
+
+	active, err := apiTokenResource(resp.Result[1], "token-2", nil)
+	require.NoError(t, err)
+	assert.Equal(t, v2.Status_RESOURCE_STATUS_ENABLED, active.GetStatus().GetStatus())
+	assert.Equal(t, false, active.GetProfile().AsMap()["ip_restricted"])
+
+	secretTrait = &v2.SecretTrait{}
+	annos = annotations.Annotations(active.GetAnnotations())
+	_, err = annos.Pick(secretTrait)
+	require.NoError(t, err)
+	assert.False(t, secretTrait.HasLastUsedAt())
+
+	assert.Equal(t, v2.Status_RESOURCE_STATUS_DISABLED, apiTokenResourceStatus(apiTokenStatusExpired))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"slices"
 
 	"github.com/cloudflare/cloudflare-go"
 	cfg "github.com/conductorone/baton-cloudflare/pkg/config"
@@ -52,9 +53,13 @@ func New(ctx context.Context, cc *cfg.Cloudflare, _ *cli.ConnectorOpts) (connect
 	}
 
 	return &Cloudflare{
-		client:    client,
-		accountId: accountId,
-		emailId:   emailId,
+		client:         client,
+		accountId:      accountId,
+		accountIds:     cc.AccountIds,
+		skipAccountIds: cc.SkipAccountIds,
+		emailId:        emailId,
+
+		revokeAccessSessionsOnDelete: cc.RevokeAccessSessionsOnDelete,
 	}, nil, nil
 }
 
@@ -104,6 +109,16 @@ func (c *Cloudflare) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error
 					Placeholder: "role-id-1",
 					Order:       3,
 				},
+				"account_id": {
+					DisplayName: "Account ID",
+					Required:    false,
+					Description: "Cloudflare account to invite the member into. Defaults to the configured account ID.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_StringField{
+						StringField: &v2.ConnectorAccountCreationSchema_StringField{},
+					},
+					Placeholder: "account-id",
+					Order:       4,
+				},
 			},
 		},
 	}, nil
@@ -119,20 +134,63 @@ func (c *Cloudflare) Validate(ctx context.Context) (annotations.Annotations, err
 		if err != nil {
 			return nil, fmt.Errorf("baton-cloudflare: failed to validate API keys: %w", err)
 		}
+
+		return nil, nil
+	}
+
+	// Without a configured account, check that the credentials can list the accounts to sync.
+	if c.client != nil {
+		_, _, err := c.client.Accounts(ctx, cloudflare.AccountsListParams{
+			PaginationOptions: cloudflare.PaginationOptions{PerPage: 1},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("baton-cloudflare: failed to validate API keys: %w", err)
+		}
 	}
 
 	return nil, nil
 }
 
+// syncAccountIds returns the accounts to sync: the account-ids list together with account-id.
+// An empty result means every account the credentials can access.
+func (c *Cloudflare) syncAccountIds() []string {
+	accountIds := slices.Clone(c.accountIds)
+	if c.accountId != "" && !slices.Contains(accountIds, c.accountId) {
+		accountIds = append(accountIds, c.accountId)
+	}
+
+	return accountIds
+}
+
 func (c *Cloudflare) Asset(_ context.Context, _ *v2.AssetRef) (string, io.ReadCloser, error) {
 	return "", nil, nil
 }
 
+func (c *Cloudflare) EventFeeds(_ context.Context) []connectorbuilder.EventFeed {
+	return []connectorbuilder.EventFeed{
+		accessAuditLogFeedBuilder(c.client, c.accountId, c.syncAccountIds(), c.skipAccountIds),
+	}
+}
+
 func (c *Cloudflare) ResourceSyncers(_ context.Context) []connectorbuilder.ResourceSyncerV2 {
 	return []connectorbuilder.ResourceSyncerV2{
-		userBuilder(c.client, c.accountId),
+		accountBuilder(c.client, c.accountId, c.emailId, c.syncAccountIds(), c.skipAccountIds),
+		userBuilder(c.client, c.accountId, c.revokeAccessSessionsOnDelete),
 		invitationBuilder(c.client, c.accountId, c.emailId),
 		roleBuilder(c.client, c.accountId, c.emailId),
+		groupBuilder(c.client, c.accountId, c.emailId),
+		accessGroupBuilder(c.client, c.accountId),
+		accessApplicationBuilder(c.client, c.accountId),
+		accessPolicyBuilder(c.client, c.accountId),
+		accessUserBuilder(c.client, c.accountId),
+		accessServiceTokenBuilder(c.client, c.accountId),
+		accessIdentityProviderBuilder(c.client, c.accountId),
+		deviceBuilder(c.client, c.accountId),
+		policyBuilder(c.client, c.accountId),
+		permissionGroupBuilder(c.client, c.accountId, c.emailId),
+		zoneBuilder(c.client, c.accountId),
+		zoneRoleBuilder(c.client, c.accountId, c.emailId),
 		apiTokenBuilder(c.client, c.accountId, c.emailId),
+		tokenPermissionGroupBuilder(c.client, c.accountId, c.emailId),
 	}
 }
@@ -0,0 +1,214 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	config "github.com/conductorone/baton-sdk/pb/c1/config/v1"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/actions"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// deviceOwnerEntitlement links a device to the user who enrolled it. It is read-only: a device
+	// belongs to whoever logged in to WARP on it.
+	deviceOwnerEntitlement = "owner"
+	revokeDeviceAction     = "revoke_device"
+)
+
+var revokeDeviceSchema = &v2.BatonActionSchema{
+	Name:        revokeDeviceAction,
+	DisplayName: "Revoke Device",
+	Description: "Revoke the device's WARP registration so it can no longer connect to Cloudflare Zero Trust, for example when a laptop is lost.",
+	Arguments: []*config.Field{
+		{
+			Name:        actionResourceIDArg,
+			DisplayName: "Device",
+			Description: "The device to revoke.",
+			IsRequired:  true,
+			Field:       &config.Field_ResourceIdField{ResourceIdField: &config.ResourceIdField{}},
+		},
+	},
+	ReturnTypes: []*config.Field{
+		{Name: "success", Field: &config.Field_BoolField{BoolField: &config.BoolField{}}},
+	},
+}
+
+type deviceResourceType struct {
+	resourceType *v2.ResourceType
+	client       *cloudflare.API
+	accountId    string
+}
+
+func (o *deviceResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+// deviceResource builds a WARP-enrolled device. Revoked devices stay listed until they are deleted,
+// with their revocation time in the profile.
+func deviceResource(device cloudflare.TeamsDeviceListItem, resourceID string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"device_id":      device.ID,
+		"device_name":    device.Name,
+		"device_type":    device.DeviceType,
+		"model":          device.Model,
+		"manufacturer":   device.Manufacturer,
+		"serial_number":  device.SerialNumber,
+		"os_version":     device.OSVersion,
+		"os_distro_name": device.OSDistroName,
+		"warp_version":   device.Version,
+		"last_seen":      device.LastSeen,
+		"revoked":        device.RevokedAt != "",
+		"revoked_at":     device.RevokedAt,
+		"owner_id":       device.User.ID,
+		"owner_email":    device.User.Email,
+		"owner_name":     device.User.Name,
+	}
+
+	opts := []rs.ResourceOption{
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithResourceProfile(profile),
+	}
+	if createdAt, ok := parseAccessUserTime(device.Created); ok {
+		opts = append(opts, rs.WithResourceCreatedAt(createdAt))
+	}
+
+	displayName := device.Name
+	if displayName == "" {
+		displayName = device.ID
+	}
+
+	return rs.NewResource(displayName, resourceTypeDevice, resourceID, opts...)
+}
+
+func (o *deviceResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, _ rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	devices, err := o.client.ListTeamsDevices(ctx, accountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: failed to list devices: %w", err)
+	}
+
+	rv := make([]*v2.Resource, 0, len(devices))
+	for _, device := range devices {
+		if device.Deleted {
+			continue
+		}
+		dr, err := deviceResource(device, accountScopedID(o.accountId, accountID, device.ID), parentResourceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		rv = append(rv, dr)
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+func (o *deviceResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
+	rv := []*v2.Entitlement{
+		ent.NewPermissionEntitlement(
+			resource,
+			deviceOwnerEntitlement,
+			ent.WithGrantableTo(resourceTypeUser, resourceTypeAccessUser),
+			ent.WithDisplayName(fmt.Sprintf("%s Owner", resource.DisplayName)),
+			ent.WithDescription(fmt.Sprintf("Enrolled the %s device in Cloudflare WARP", resource.DisplayName)),
+			ent.WithAnnotation(&v2.EntitlementImmutable{}),
+		),
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// Grants grants the owner entitlement to the Access user who enrolled the device and, when their
+// email belongs to an account member, to that member too. The owner is read from the profile the
+// device was synced with.
+func (o *deviceResourceType) Grants(ctx context.Context, resource *v2.Resource, opts rs.SyncOpAttrs) ([]*v2.Grant, *rs.SyncOpResults, error) {
+	accountID, _ := parseAccountScopedID(o.accountId, resource.Id.Resource)
+	profile := rs.GetProfile(resource)
+	ownerID, _ := rs.GetProfileStringValue(profile, "owner_id")
+	if ownerID == "" {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	accessUserID := &v2.ResourceId{
+		ResourceType: resourceTypeAccessUser.Id,
+		Resource:     accountScopedID(o.accountId, accountID, ownerID),
+	}
+	rv := []*v2.Grant{
+		grant.NewGrant(resource, deviceOwnerEntitlement, accessUserID, grant.WithAnnotation(&v2.GrantImmutable{})),
+	}
+
+	ownerEmail, _ := rs.GetProfileStringValue(profile, "owner_email")
+	if ownerEmail == "" {
+		return rv, &rs.SyncOpResults{}, nil
+	}
+	members, err := accountMembersSnapshot(ctx, o.client, opts.Session, accountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: could not retrieve account members: %w", err)
+	}
+	for _, member := range members {
+		if member.User.ID == "" || !strings.EqualFold(member.User.Email, ownerEmail) {
+			continue
+		}
+
+		ur, err := userResource(member, accountScopedID(o.accountId, accountID, member.User.ID), accountResourceID(accountID))
+		if err != nil {
+			return nil, nil, wrapError(err, "failed to create user resource")
+		}
+		rv = append(rv, grant.NewGrant(resource, deviceOwnerEntitlement, ur.Id, grant.WithAnnotation(&v2.GrantImmutable{})))
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+func (o *deviceResourceType) ResourceActions(ctx context.Context, registry actions.ActionRegistry) error {
+	return registry.Register(ctx, revokeDeviceSchema, o.revokeDevice)
+}
+
+// revokeDevice is the revoke_device action. A revoked device must be re-enrolled before it can
+// connect again.
+func (o *deviceResourceType) revokeDevice(ctx context.Context, args *structpb.Struct) (*structpb.Struct, annotations.Annotations, error) {
+	resourceID, err := actions.RequireResourceIDArg(args, actionResourceIDArg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: %w", err)
+	}
+	if resourceID.ResourceType != resourceTypeDevice.Id {
+		return nil, nil, fmt.Errorf("baton-cloudflare: invalid resource type for %s: %s", revokeDeviceAction, resourceID.ResourceType)
+	}
+
+	accountID, deviceID := parseAccountScopedID(o.accountId, resourceID.Resource)
+	if accountID == "" {
+		return nil, nil, ErrMissingAccountID
+	}
+
+	_, err = o.client.RevokeTeamsDevices(ctx, accountID, []string{deviceID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: failed to revoke device: %w", err)
+	}
+
+	rv := &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			"success": structpb.NewBoolValue(true),
+		},
+	}
+
+	return rv, nil, nil
+}
+
+func deviceBuilder(client *cloudflare.API, accountId string) *deviceResourceType {
+	return &deviceResourceType{
+		resourceType: resourceTypeDevice,
+		client:       client,
+		accountId:    accountId,
+	}
+}
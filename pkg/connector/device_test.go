@@ -0,0 +1,85 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/conductorone/baton-sdk/pkg/actions"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceResource(t *testing.T) {
+	device := cloudflare.TeamsDeviceListItem{
+		ID:           "device-1",
+		Name:         "Jane's MacBook",
+		DeviceType:   "mac",
+		SerialNumber: "C02XYZ",
+		OSVersion:    "14.4.1",
+		Created:      "2024-01-02T03:04:05Z",
+		LastSeen:     "2024-05-06T07:08:09Z",
+		RevokedAt:    "2024-05-07T00:00:00Z",
+		User:         cloudflare.UserItem{ID: "access-user-1", Email: "jane@example.com"},
+	}
+
+	resource, err := deviceResource(device, accountScopedID("acct-1", "acct-1", device.ID), accountResourceID("acct-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "device-1", resource.GetId().GetResource())
+	assert.Equal(t, resourceTypeDevice.GetId(), resource.GetId().GetResourceType())
+
+	profile := resource.GetProfile().AsMap()
+	assert.Equal(t, "C02XYZ", profile["serial_number"])
+	assert.Equal(t, "mac", profile["device_type"])
+	assert.Equal(t, true, profile["revoked"])
+	assert.Equal(t, "jane@example.com", profile["owner_email"])
+}
+
+func TestRevokeDeviceActionSchema(t *testing.T) {
+	ctx := context.Background()
+	manager := actions.NewActionManager(ctx)
+	registry, err := manager.GetTypeRegistry(ctx, resourceTypeDevice.Id)
+	require.NoError(t, err)
+	require.NoError(t, deviceBuilder(nil, "acct-1").ResourceActions(ctx, registry))
+
+	schemas, _, err := manager.ListActionSchemas(ctx, resourceTypeDevice.Id)
+	require.NoError(t, err)
+	require.Len(t, schemas, 1)
+	assert.Equal(t, revokeDeviceAction, schemas[0].GetName())
+	assert.Equal(t, resourceTypeDevice.GetId(), schemas[0].GetResourceTypeId())
+}
+
+// The owner comes from the device profile, so grants need no device lookup.
+func TestDeviceGrantsFromProfile(t *testing.T) {
+	ctx := context.Background()
+	ss := newMapSessionStore()
+	require.NoError(t, setMemberSnapshot(ctx, ss, "acct-1", [][]cloudflare.AccountMember{{
+		{ID: "member-1", User: cloudflare.AccountMemberUserDetails{ID: "user-1", Email: "Jane@example.com"}},
+		{ID: "member-2", User: cloudflare.AccountMemberUserDetails{ID: "user-2", Email: "john@example.com"}},
+	}}))
+
+	device := cloudflare.TeamsDeviceListItem{
+		ID:   "device-1",
+		Name: "Jane's MacBook",
+		User: cloudflare.UserItem{ID: "access-user-1", Email: "jane@example.com"},
+	}
+	resource, err := deviceResource(device, device.ID, accountResourceID("acct-1"))
+	require.NoError(t, err)
+
+	o := deviceBuilder(nil, "acct-1")
+	grants, _, err := o.Grants(ctx, resource, rs.SyncOpAttrs{Session: ss})
+	require.NoError(t, err)
+	require.Len(t, grants, 2)
+	assert.Equal(t, resourceTypeAccessUser.Id, grants[0].GetPrincipal().GetId().GetResourceType())
+	assert.Equal(t, "access-user-1", grants[0].GetPrincipal().GetId().GetResource())
+	assert.Equal(t, resourceTypeUser.Id, grants[1].GetPrincipal().GetId().GetResourceType())
+	assert.Equal(t, "user-1", grants[1].GetPrincipal().GetId().GetResource())
+
+	// Devices without an owner have no grants.
+	unowned, err := deviceResource(cloudflare.TeamsDeviceListItem{ID: "device-2"}, "device-2", accountResourceID("acct-1"))
+	require.NoError(t, err)
+	grants, _, err = o.Grants(ctx, unowned, rs.SyncOpAttrs{Session: ss})
+	require.NoError(t, err)
+	assert.Empty(t, grants)
+}
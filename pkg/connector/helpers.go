@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/cloudflare/cloudflare-go"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
 	"google.golang.org/protobuf/proto"
 )
@@ -95,26 +97,66 @@ func WithAuthorizationBearerHeader(token string) uhttp.RequestOption {
 // findMemberIDByUserID looks up the Cloudflare membership ID for a given user UUID.
 // The resource ID stored in baton is the user UUID (member.User.ID), but Cloudflare's
 // delete and update APIs require the membership ID (member.ID).
-func findMemberIDByUserID(ctx context.Context, client *cloudflare.API, accountID, userID string) (string, error) {
-	perPage := 50
+// The lookup reads the sync's member snapshot when a session store is given.
+func findMemberIDByUserID(ctx context.Context, client *cloudflare.API, ss sessions.SessionStore, accountID, userID string) (string, error) {
+	member, err := findMemberByUserID(ctx, client, ss, accountID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	return member.ID, nil
+}
+
+// findMemberByUserID looks up the account membership of a given user UUID.
+func findMemberByUserID(ctx context.Context, client *cloudflare.API, ss sessions.SessionStore, accountID, userID string) (cloudflare.AccountMember, error) {
+	members, err := accountMembersSnapshot(ctx, client, ss, accountID)
+	if err != nil {
+		return cloudflare.AccountMember{}, err
+	}
+
+	for _, m := range members {
+		if m.User.ID == userID {
+			return m, nil
+		}
+	}
+
+	return cloudflare.AccountMember{}, fmt.Errorf("baton-cloudflare: %w for user ID %s", errMemberNotFound, userID)
+}
+
+// listAllAccountMembers pages through every member of the account, including pending invitations.
+func listAllAccountMembers(ctx context.Context, client *cloudflare.API, accountID string) ([]cloudflare.AccountMember, error) {
+	pages, err := listAccountMemberPages(ctx, client, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rv []cloudflare.AccountMember
+	for _, page := range pages {
+		rv = append(rv, page...)
+	}
+
+	return rv, nil
+}
+
+// listAccountMemberPages returns every member of the account, one slice per API page.
+func listAccountMemberPages(ctx context.Context, client *cloudflare.API, accountID string) ([][]cloudflare.AccountMember, error) {
 	page := 1
 	processed := 0
+	var rv [][]cloudflare.AccountMember
 
 	for {
 		members, resp, err := client.AccountMembers(ctx, accountID, cloudflare.PaginationOptions{
 			Page:    page,
-			PerPage: perPage,
+			PerPage: accountMembersPerPage,
 		})
 		if err != nil {
-			return "", fmt.Errorf("baton-cloudflare: failed to list account members: %w", err)
+			return nil, fmt.Errorf("baton-cloudflare: failed to list account members: %w", err)
 		}
-
-		for _, m := range members {
-			if m.User.ID == userID {
-				return m.ID, nil
-			}
+		if len(members) == 0 {
+			break
 		}
 
+		rv = append(rv, members)
 		processed += len(members)
 		if processed >= resp.Total {
 			break
@@ -122,7 +164,7 @@ func findMemberIDByUserID(ctx context.Context, client *cloudflare.API, accountID
 		page++
 	}
 
-	return "", fmt.Errorf("baton-cloudflare: %w for user ID %s", errMemberNotFound, userID)
+	return rv, nil
 }
 
 // getAccountInfo extracts the primary email and optional first/last name from AccountInfo.
@@ -179,3 +221,14 @@ func getRoleIDsFromProfile(accountInfo *v2.AccountInfo) []string {
 	}
 	return roleIDs
 }
+
+// getAccountIDFromProfile returns the optional "account_id" field of the account info profile.
+func getAccountIDFromProfile(accountInfo *v2.AccountInfo) string {
+	profile := accountInfo.GetProfile()
+	if profile == nil {
+		return ""
+	}
+	accountID, _ := profile.AsMap()["account_id"].(string)
+
+	return strings.TrimSpace(accountID)
+}
@@ -73,10 +73,10 @@ func TestResourceTypeGrantFails(t *testing.T) {
 		t.Skip()
 	}
 	accUser := getAccountMemberForTesting(accountID, userId, userEmail)
-	principal, err := userResource(*accUser)
+	principal, err := userResource(*accUser, accUser.User.ID, nil)
 	assert.Nil(t, err)
 	role := getRoleForTesting(roleId, resourceDisplayName, roleEntitlement)
-	resource, err := roleResource(*role, resourceTypeRole, nil)
+	resource, err := roleResource(*role, resourceTypeRole, role.ID, nil)
 	assert.Nil(t, err)
 	entitlement := getEntitlementForTesting(resource, resourceDisplayName, roleEntitlement)
 	client = getClientForTesting(apiToken, apiKey)
@@ -99,10 +99,10 @@ func TestResourceTypeGrant(t *testing.T) {
 		t.Skip()
 	}
 	accUser := getAccountMemberForTesting(accountID, userId, userEmail)
-	principal, err := userResource(*accUser)
+	principal, err := userResource(*accUser, accUser.User.ID, nil)
 	assert.Nil(t, err)
 	role := getRoleForTesting(roleId, resourceDisplayName, roleEntitlement)
-	resource, err := roleResource(*role, resourceTypeRole, nil)
+	resource, err := roleResource(*role, resourceTypeRole, role.ID, nil)
 	assert.Nil(t, err)
 	entitlement := getEntitlementForTesting(resource, resourceDisplayName, roleEntitlement)
 	client = getClientForTesting(apiToken, apiKey)
@@ -125,10 +125,10 @@ func TestResourceTypeRevoke(t *testing.T) {
 		t.Skip()
 	}
 	accUser := getAccountMemberForTesting(accountID, userId, userEmail)
-	ur, err := userResource(*accUser)
+	ur, err := userResource(*accUser, accUser.User.ID, nil)
 	assert.Nil(t, err)
 	role := getRoleForTesting(roleId, resourceDisplayName, roleEntitlement)
-	resource, err := roleResource(*role, resourceTypeRole, nil)
+	resource, err := roleResource(*role, resourceTypeRole, role.ID, nil)
 	assert.Nil(t, err)
 	client = getClientForTesting(apiToken, apiKey)
 	roleBuilder := getRoleBuilderForTesting(client)
@@ -34,7 +34,7 @@ func (o *InvitationResourceType) ResourceType(_ context.Context) *v2.ResourceTyp
 	return o.resourceType
 }
 
-func invitationResource(member cloudflare.AccountMember) (*v2.Resource, error) {
+func invitationResource(member cloudflare.AccountMember, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
 	email := member.User.Email
 	status := cases.Title(language.English).String(member.Status)
 	profile := map[string]interface{}{
@@ -54,6 +54,7 @@ func invitationResource(member cloudflare.AccountMember) (*v2.Resource, error) {
 		resourceTypeInvitation,
 		member.ID,
 		userTraits,
+		rs.WithParentResourceID(parentResourceID),
 		rs.WithResourceProfile(profile),
 		rs.WithResourceStatus(v2.Status_RESOURCE_STATUS_ENABLED, status),
 	)
@@ -72,7 +73,7 @@ func invitationResource(member cloudflare.AccountMember) (*v2.Resource, error) {
 // (GET /accounts/{id}/members?status=pending|accepted|rejected).
 // Until the SDK adds a dedicated filter params struct we call the endpoint directly so that
 // only pending invitations are returned, avoiding a full member scan on every sync.
-func (o *InvitationResourceType) listPendingMembers(ctx context.Context, page int) ([]cloudflare.AccountMember, cloudflare.ResultInfo, error) {
+func (o *InvitationResourceType) listPendingMembers(ctx context.Context, accountID string, page int) ([]cloudflare.AccountMember, cloudflare.ResultInfo, error) {
 	httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
 	if err != nil {
 		return nil, cloudflare.ResultInfo{}, err
@@ -83,7 +84,7 @@ func (o *InvitationResourceType) listPendingMembers(ctx context.Context, page in
 	params := url.Values{}
 	params.Set("status", userStatusPending)
 	params.Set("page", strconv.Itoa(page))
-	endpointURL := fmt.Sprintf("%s/accounts/%s/members?%s", o.client.BaseURL, o.accountId, params.Encode())
+	endpointURL := fmt.Sprintf("%s/accounts/%s/members?%s", o.client.BaseURL, accountID, params.Encode())
 
 	uri, err := url.Parse(endpointURL)
 	if err != nil {
@@ -116,13 +117,18 @@ func (o *InvitationResourceType) listPendingMembers(ctx context.Context, page in
 	return response.Result, response.ResultInfo, nil
 }
 
-func (o *InvitationResourceType) List(ctx context.Context, _ *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+func (o *InvitationResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
 	page, err := convertPageToken(opts.PageToken.Token)
 	if err != nil {
 		return nil, nil, fmt.Errorf("baton-cloudflare: invalid page token error")
 	}
 
-	members, resultInfo, err := o.listPendingMembers(ctx, page)
+	members, resultInfo, err := o.listPendingMembers(ctx, accountID, page)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -130,7 +136,7 @@ func (o *InvitationResourceType) List(ctx context.Context, _ *v2.ResourceId, opt
 	nextPage := convertNextPageToken(resultInfo.Page, len(members))
 	rv := make([]*v2.Resource, 0, len(members))
 	for _, member := range members {
-		resource, err := invitationResource(member)
+		resource, err := invitationResource(member, parentResourceID)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -150,12 +156,18 @@ func (o *InvitationResourceType) Grants(_ context.Context, _ *v2.Resource, _ rs.
 
 // Delete cancels a pending invitation.
 // The resource ID is the membership UUID (member.ID), which is what the Cloudflare delete API requires.
-func (o *InvitationResourceType) Delete(ctx context.Context, resourceId *v2.ResourceId, _ *v2.ResourceId) (annotations.Annotations, error) {
+// The invitation is cancelled in its parent account, or in the configured account when no parent is given.
+func (o *InvitationResourceType) Delete(ctx context.Context, resourceId *v2.ResourceId, parentResourceID *v2.ResourceId) (annotations.Annotations, error) {
 	if resourceId.ResourceType != resourceTypeInvitation.Id {
 		return nil, fmt.Errorf("baton-cloudflare: invalid resource type for delete: %s", resourceId.ResourceType)
 	}
 
-	err := o.client.DeleteAccountMember(ctx, o.accountId, resourceId.Resource)
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		accountID = o.accountId
+	}
+
+	err := o.client.DeleteAccountMember(ctx, accountID, resourceId.Resource)
 	if err != nil {
 		var notFound *cloudflare.NotFoundError
 		if errors.As(err, &notFound) {
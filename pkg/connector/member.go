@@ -0,0 +1,158 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+// authRequestOptions returns the headers every raw uhttp call to the Cloudflare API needs,
+// covering both API token and email + global API key authentication.
+func authRequestOptions(client *cloudflare.API, emailId string) []uhttp.RequestOption {
+	opts := []uhttp.RequestOption{
+		uhttp.WithAcceptJSONHeader(),
+	}
+	if client.APIToken != "" {
+		opts = append(opts, uhttp.WithBearerToken(client.APIToken))
+	}
+	if emailId != "" {
+		opts = append(opts, uhttp.WithHeader(XAuthEmailHeaderKey, emailId))
+	}
+	if client.APIKey != "" {
+		opts = append(opts, uhttp.WithHeader(XAuthKeyHeaderKey, client.APIKey))
+	}
+
+	return opts
+}
+
+// memberIDForPrincipal resolves the Cloudflare membership ID of a user principal.
+// The member ID is written to the resource-level profile during sync; rs.GetProfile
+// falls back to the deprecated trait profile for resources synced before the move,
+// and an API lookup covers anything still missing it. A user belongs to every account
+// it is a member of but carries the membership of only one, so the profile is trusted
+// only when the principal was synced under accountID (or before accounts were synced).
+// Invitation principals already carry the membership ID as their resource ID.
+func memberIDForPrincipal(ctx context.Context, client *cloudflare.API, accountID string, principal *v2.Resource) (string, error) {
+	if accountID == "" {
+		return "", ErrMissingAccountID
+	}
+	if principal.Id.ResourceType == resourceTypeInvitation.Id {
+		return principal.Id.Resource, nil
+	}
+
+	parent := principal.GetParentResourceId()
+	if parent == nil || parent.GetResource() == accountID {
+		memberId, found := rs.GetProfileStringValue(rs.GetProfile(principal), memberIdProfileKey)
+		if found && memberId != "" {
+			return memberId, nil
+		}
+	}
+
+	// User IDs from other accounts are account-scoped; the user UUID is the same in every account.
+	_, userID := parseAccountScopedID(accountID, principal.Id.Resource)
+	return findMemberIDByUserID(ctx, client, nil, accountID, userID)
+}
+
+// getAccountMember returns an account member, including its roles and policies.
+func getAccountMember(ctx context.Context, client *cloudflare.API, emailId, accountID, memberID string) (*cloudflare.AccountMemberDetailResponse, error) {
+	var accountMemberResponse = &cloudflare.AccountMemberDetailResponse{}
+	if accountID == "" {
+		return accountMemberResponse, ErrMissingAccountID
+	}
+
+	httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
+	if err != nil {
+		return nil, err
+	}
+
+	baseClient := uhttp.NewBaseHttpClient(httpClient)
+	endpointUrl := fmt.Sprintf("%s/accounts/%s/members/%s", client.BaseURL, accountID, memberID)
+	uri, err := url.Parse(endpointUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := baseClient.NewRequest(ctx, http.MethodGet, uri, authRequestOptions(client, emailId)...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := baseClient.Do(req, uhttp.WithJSONResponse(&accountMemberResponse))
+	if err != nil {
+		return nil, fmt.Errorf("baton-cloudflare: failed to get account member: %w", err)
+	}
+
+	defer resp.Body.Close()
+	return accountMemberResponse, nil
+}
+
+// putAccountMember sends an update to
+// https://developers.cloudflare.com/api/operations/account-members-update-member.
+// Cloudflare rejects a body carrying both roles and policies, so callers send one or the other.
+func putAccountMember(ctx context.Context, client *cloudflare.API, emailId, accountID, memberID string, body interface{}) (*cloudflare.AccountMember, error) {
+	var accountMemberResponse = &Response{}
+	if accountID == "" {
+		return nil, ErrMissingAccountID
+	}
+
+	httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
+	if err != nil {
+		return nil, err
+	}
+
+	baseClient := uhttp.NewBaseHttpClient(httpClient)
+	endpointUrl := fmt.Sprintf("%s/accounts/%s/members/%s", client.BaseURL, accountID, memberID)
+	uri, err := url.Parse(endpointUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := append([]uhttp.RequestOption{uhttp.WithJSONBody(body)}, authRequestOptions(client, emailId)...)
+	req, err := baseClient.NewRequest(ctx, http.MethodPut, uri, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := baseClient.Do(req, uhttp.WithJSONResponse(&accountMemberResponse))
+	if err != nil {
+		ce := &CloudflareError{
+			ErrorMessage:     err.Error(),
+			ErrorDescription: err.Error(),
+			ErrorLink:        endpointUrl,
+		}
+		if resp != nil {
+			ce.ErrorCode = resp.StatusCode
+			bodyBytes, err := io.ReadAll(resp.Body)
+			if err != nil {
+				ce.ErrorSummary = fmt.Sprintf("Error reading response body %s", err.Error())
+				return nil, ce
+			}
+
+			ce.ErrorSummary = string(bodyBytes)
+		}
+
+		return nil, ce
+	}
+
+	defer resp.Body.Close()
+	return &accountMemberResponse.Result, nil
+}
+
+// updateAccountMemberPolicies replaces the member's policies with the given set.
+func updateAccountMemberPolicies(ctx context.Context, client *cloudflare.API, emailId, accountID, memberID string, policies []cloudflare.Policy) (*cloudflare.AccountMember, error) {
+	body := struct {
+		Policies []cloudflare.Policy `json:"policies"`
+	}{
+		Policies: policies,
+	}
+
+	return putAccountMember(ctx, client, emailId, accountID, memberID, body)
+}
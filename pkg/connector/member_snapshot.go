@@ -0,0 +1,114 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/conductorone/baton-sdk/pkg/session"
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
+)
+
+const (
+	accountMembersPerPage   = 50
+	memberSnapshotKeyPrefix = "account_members"
+)
+
+// snapshotIndex records how many pages of a paged snapshot, such as an account's members, are
+// stored. It is written after the pages, so a snapshot is only read back once it is complete.
+type snapshotIndex struct {
+	Pages int `json:"pages"`
+}
+
+func memberSnapshotKey(accountID string) string {
+	return fmt.Sprintf("%s:%s", memberSnapshotKeyPrefix, accountID)
+}
+
+func memberSnapshotPageKey(accountID string, page int) string {
+	return fmt.Sprintf("%s:%s:%d", memberSnapshotKeyPrefix, accountID, page)
+}
+
+// accountMembersSnapshot returns every member of the account, including pending invitations.
+// During a sync the members are fetched from Cloudflare once and kept in the session store,
+// which is scoped to the sync, so users, roles, policies and the other member-based grants all
+// read the same snapshot instead of paging the members API for every resource. The snapshot is
+// stored one API page per key to stay under the session store's value size limit.
+// Without a session store, as in provisioning, the members are fetched directly.
+func accountMembersSnapshot(ctx context.Context, client *cloudflare.API, ss sessions.SessionStore, accountID string) ([]cloudflare.AccountMember, error) {
+	if ss == nil {
+		return listAllAccountMembers(ctx, client, accountID)
+	}
+
+	members, found, err := getMemberSnapshot(ctx, ss, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return members, nil
+	}
+
+	pages, err := listAccountMemberPages(ctx, client, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setMemberSnapshot(ctx, ss, accountID, pages); err != nil {
+		return nil, err
+	}
+
+	for _, page := range pages {
+		members = append(members, page...)
+	}
+
+	return members, nil
+}
+
+// getMemberSnapshot reads a stored member snapshot. A snapshot with missing pages is reported
+// as not found so the caller fetches the members again.
+func getMemberSnapshot(ctx context.Context, ss sessions.SessionStore, accountID string) ([]cloudflare.AccountMember, bool, error) {
+	index, found, err := session.GetJSON[snapshotIndex](ctx, ss, memberSnapshotKey(accountID))
+	if err != nil {
+		return nil, false, fmt.Errorf("baton-cloudflare: failed to read account member snapshot: %w", err)
+	}
+	if !found {
+		return nil, false, nil
+	}
+
+	keys := make([]string, 0, index.Pages)
+	for page := 1; page <= index.Pages; page++ {
+		keys = append(keys, memberSnapshotPageKey(accountID, page))
+	}
+
+	pages, err := session.GetManyJSON[[]cloudflare.AccountMember](ctx, ss, keys)
+	if err != nil {
+		return nil, false, fmt.Errorf("baton-cloudflare: failed to read account member snapshot: %w", err)
+	}
+	if len(pages) != len(keys) {
+		return nil, false, nil
+	}
+
+	members := []cloudflare.AccountMember{}
+	for _, key := range keys {
+		members = append(members, pages[key]...)
+	}
+
+	return members, true, nil
+}
+
+func setMemberSnapshot(ctx context.Context, ss sessions.SessionStore, accountID string, pages [][]cloudflare.AccountMember) error {
+	items := make(map[string][]cloudflare.AccountMember, len(pages))
+	for i, page := range pages {
+		items[memberSnapshotPageKey(accountID, i+1)] = page
+	}
+
+	if err := session.SetManyJSON(ctx, ss, items); err != nil {
+		return fmt.Errorf("baton-cloudflare: failed to store account member snapshot: %w", err)
+	}
+
+	err := session.SetJSON(ctx, ss, memberSnapshotKey(accountID), snapshotIndex{Pages: len(pages)})
+	if err != nil {
+		return fmt.Errorf("baton-cloudflare: failed to store account member snapshot: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapSessionStore is a minimal in-memory session store.
+type mapSessionStore struct {
+	values map[string][]byte
+}
+
+func newMapSessionStore() *mapSessionStore {
+	return &mapSessionStore{values: map[string][]byte{}}
+}
+
+func (m *mapSessionStore) Get(_ context.Context, key string, _ ...sessions.SessionStoreOption) ([]byte, bool, error) {
+	v, ok := m.values[key]
+	return v, ok, nil
+}
+
+func (m *mapSessionStore) GetMany(_ context.Context, keys []string, _ ...sessions.SessionStoreOption) (map[string][]byte, []string, error) {
+	rv := map[string][]byte{}
+	for _, key := range keys {
+		if v, ok := m.values[key]; ok {
+			rv[key] = v
+		}
+	}
+	return rv, nil, nil
+}
+
+func (m *mapSessionStore) Set(_ context.Context, key string, value []byte, _ ...sessions.SessionStoreOption) error {
+	m.values[key] = value
+	return nil
+}
+
+func (m *mapSessionStore) SetMany(_ context.Context, values map[string][]byte, _ ...sessions.SessionStoreOption) error {
+	for key, value := range values {
+		m.values[key] = value
+	}
+	return nil
+}
+
+func (m *mapSessionStore) Delete(_ context.Context, key string, _ ...sessions.SessionStoreOption) error {
+	delete(m.values, key)
+	return nil
+}
+
+func (m *mapSessionStore) Clear(_ context.Context, _ ...sessions.SessionStoreOption) error {
+	m.values = map[string][]byte{}
+	return nil
+}
+
+func (m *mapSessionStore) GetAll(_ context.Context, _ string, _ ...sessions.SessionStoreOption) (map[string][]byte, string, error) {
+	return m.values, "", nil
+}
+
+func TestAccountMembersSnapshotReadsStoredPages(t *testing.T) {
+	ctx := context.Background()
+	ss := newMapSessionStore()
+	pages := [][]cloudflare.AccountMember{
+		{{ID: "member-1"}, {ID: "member-2"}},
+		{{ID: "member-3", User: cloudflare.AccountMemberUserDetails{ID: "user-3"}}},
+	}
+	require.NoError(t, setMemberSnapshot(ctx, ss, "acct-1", pages))
+
+	// A stored snapshot is served without calling the Cloudflare API.
+	members, err := accountMembersSnapshot(ctx, nil, ss, "acct-1")
+	require.NoError(t, err)
+	require.Len(t, members, 3)
+	assert.Equal(t, "member-1", members[0].ID)
+	assert.Equal(t, "member-3", members[2].ID)
+
+	memberID, err := findMemberIDByUserID(ctx, nil, ss, "acct-1", "user-3")
+	require.NoError(t, err)
+	assert.Equal(t, "member-3", memberID)
+
+	_, err = findMemberIDByUserID(ctx, nil, ss, "acct-1", "user-missing")
+	assert.ErrorIs(t, err, errMemberNotFound)
+}
+
+func TestMemberSnapshotIncomplete(t *testing.T) {
+	ctx := context.Background()
+	ss := newMapSessionStore()
+	require.NoError(t, setMemberSnapshot(ctx, ss, "acct-1", [][]cloudflare.AccountMember{{{ID: "member-1"}}, {{ID: "member-2"}}}))
+	require.NoError(t, ss.Delete(ctx, memberSnapshotPageKey("acct-1", 2)))
+
+	_, found, err := getMemberSnapshot(ctx, ss, "acct-1")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// Snapshots are kept per account.
+	_, found, err = getMemberSnapshot(ctx, ss, "acct-2")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
@@ -3,9 +3,13 @@ package connector
 import "github.com/cloudflare/cloudflare-go"
 
 type Cloudflare struct {
-	client    *cloudflare.API
-	accountId string
-	emailId   string
+	client         *cloudflare.API
+	accountId      string
+	accountIds     []string
+	skipAccountIds []string
+	emailId        string
+
+	revokeAccessSessionsOnDelete bool
 }
 
 type Response struct {
@@ -0,0 +1,250 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/session"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
+)
+
+const (
+	// accountScopeKey is the resource scope Cloudflare uses for permission groups that apply account-wide.
+	accountScopeKey         = "com.cloudflare.api.account"
+	permissionGroupScopeKey = "scopes"
+	permissionGroupLabelKey = "label"
+	accountScopeWildcard    = "*"
+
+	permissionGroupSnapshotKeyPrefix = "account_permission_groups"
+)
+
+// permissionGroupResourceType models an IAM permission group granted account-wide through a
+// member policy. It is the policy-based counterpart of roleResourceType: Grant and Revoke edit
+// the member's policies the same way role provisioning edits the member's roles.
+type permissionGroupResourceType struct {
+	resourceType *v2.ResourceType
+	client       *cloudflare.API
+	accountId    string
+	emailId      string
+}
+
+func (o *permissionGroupResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+// permissionGroupHasScope reports whether a permission group can be bound to the given scope.
+// Groups that do not advertise their scopes are kept rather than silently dropped.
+func permissionGroupHasScope(pg cloudflare.PermissionGroup, scope string) bool {
+	scopes, ok := pg.Meta[permissionGroupScopeKey]
+	if !ok || scopes == "" {
+		return true
+	}
+
+	for _, s := range strings.Split(scopes, ",") {
+		if strings.TrimSpace(s) == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resourceGroupCoversAccount reports whether a policy resource group spans the whole account,
+// as opposed to a subset of its zones.
+func resourceGroupCoversAccount(rg cloudflare.ResourceGroup, accountID string) bool {
+	if rg.Scope.Key != fmt.Sprintf("%s.%s", accountScopeKey, accountID) {
+		return false
+	}
+	if len(rg.Scope.ScopeObjects) == 0 {
+		return true
+	}
+	for _, obj := range rg.Scope.ScopeObjects {
+		if obj.Key == accountScopeWildcard {
+			return true
+		}
+	}
+
+	return false
+}
+
+// permissionGroupResource builds the resource for a permission group. Permission group IDs repeat
+// across accounts, so resourceID is the account-scoped form of pg.ID.
+func permissionGroupResource(pg cloudflare.PermissionGroup, resourceID string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	permissions := make([]string, 0, len(pg.Permissions))
+	for _, p := range pg.Permissions {
+		permissions = append(permissions, p.Key)
+	}
+
+	profile := map[string]interface{}{
+		"permission_group_id":   pg.ID,
+		"permission_group_name": pg.Name,
+		"label":                 pg.Meta[permissionGroupLabelKey],
+		"scopes":                pg.Meta[permissionGroupScopeKey],
+		"permissions":           strings.Join(permissions, ","),
+	}
+
+	displayName := pg.Name
+	if displayName == "" {
+		displayName = pg.ID
+	}
+
+	return rs.NewRoleResource(
+		displayName,
+		resourceTypePermissionGroup,
+		resourceID,
+		nil,
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithResourceProfile(profile),
+	)
+}
+
+// accountPermissionGroupsSnapshot returns the account's permission groups. During a sync they are
+// listed once per account and kept in the session store, so the permission groups and the zone
+// roles of every zone read the same list; without one they are listed directly.
+func accountPermissionGroupsSnapshot(ctx context.Context, client *cloudflare.API, ss sessions.SessionStore, accountID string) ([]cloudflare.PermissionGroup, error) {
+	key := fmt.Sprintf("%s:%s", permissionGroupSnapshotKeyPrefix, accountID)
+	if ss != nil {
+		permissionGroups, found, err := session.GetJSON[[]cloudflare.PermissionGroup](ctx, ss, key)
+		if err != nil {
+			return nil, fmt.Errorf("baton-cloudflare: failed to read permission group snapshot: %w", err)
+		}
+		if found {
+			return permissionGroups, nil
+		}
+	}
+
+	// ListPermissionGroups is not paginated; Cloudflare returns every group in one response.
+	permissionGroups, err := client.ListPermissionGroups(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.ListPermissionGroupParams{})
+	if err != nil {
+		return nil, fmt.Errorf("baton-cloudflare: could not retrieve permission groups: %w", err)
+	}
+
+	if ss != nil {
+		if err := session.SetJSON(ctx, ss, key, permissionGroups); err != nil {
+			return nil, fmt.Errorf("baton-cloudflare: failed to store permission group snapshot: %w", err)
+		}
+	}
+
+	return permissionGroups, nil
+}
+
+func (o *permissionGroupResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	permissionGroups, err := accountPermissionGroupsSnapshot(ctx, o.client, opts.Session, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rv := make([]*v2.Resource, 0, len(permissionGroups))
+	for _, pg := range permissionGroups {
+		if !permissionGroupHasScope(pg, accountScopeKey) {
+			continue
+		}
+		pgr, err := permissionGroupResource(pg, accountScopedID(o.accountId, accountID, pg.ID), parentResourceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		rv = append(rv, pgr)
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+func (o *permissionGroupResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
+	rv := []*v2.Entitlement{
+		ent.NewAssignmentEntitlement(
+			resource,
+			roleMemberEntitlement,
+			ent.WithGrantableTo(resourceTypeUser),
+			ent.WithDisplayName(
+				fmt.Sprintf("%s Permission Group Member", resource.DisplayName),
+			),
+			ent.WithDescription(
+				fmt.Sprintf("Has the %s permission group across the Cloudflare account", resource.DisplayName),
+			),
+		),
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// Grants reads the sync's shared member snapshot, so every grant is returned in one page.
+func (o *permissionGroupResourceType) Grants(ctx context.Context, resource *v2.Resource, opts rs.SyncOpAttrs) ([]*v2.Grant, *rs.SyncOpResults, error) {
+	accountID, permissionGroupID := parseAccountScopedID(o.accountId, resource.Id.Resource)
+	members, err := accountMembersSnapshot(ctx, o.client, opts.Session, accountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: could not retrieve account members: %w", err)
+	}
+
+	coversAccount := func(rg cloudflare.ResourceGroup) bool {
+		return resourceGroupCoversAccount(rg, accountID)
+	}
+	var rv []*v2.Grant
+	for _, member := range members {
+		// Pending invitations have no User.ID yet.
+		if member.User.ID == "" {
+			continue
+		}
+		if !hasAllowBinding(member.Policies, permissionGroupID, coversAccount) {
+			continue
+		}
+
+		ur, err := userResource(member, accountScopedID(o.accountId, accountID, member.User.ID), accountResourceID(accountID))
+		if err != nil {
+			return nil, nil, wrapError(err, "failed to create user resource")
+		}
+		rv = append(rv, grant.NewGrant(resource, roleMemberEntitlement, ur.Id))
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// Grant adds an allow policy binding the permission group to the whole account.
+func (o *permissionGroupResourceType) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	if principal.Id.ResourceType != resourceTypeUser.Id {
+		return nil, fmt.Errorf("baton-cloudflare: only users can be granted permission group membership")
+	}
+
+	accountID, permissionGroupID := parseAccountScopedID(o.accountId, entitlement.Resource.Id.Resource)
+
+	return grantMemberPolicy(
+		ctx, o.client, o.emailId, accountID, principal, permissionGroupID,
+		cloudflare.NewResourceGroupForAccount(cloudflare.Account{ID: accountID}),
+		func(rg cloudflare.ResourceGroup) bool {
+			return resourceGroupCoversAccount(rg, accountID)
+		},
+	)
+}
+
+func (o *permissionGroupResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	principal := grant.Principal
+	if principal.Id.ResourceType != resourceTypeUser.Id {
+		return nil, fmt.Errorf("baton-cloudflare: only users can have permission group membership revoked")
+	}
+
+	accountID, permissionGroupID := parseAccountScopedID(o.accountId, grant.Entitlement.Resource.Id.Resource)
+
+	return revokeMemberPolicy(ctx, o.client, o.emailId, accountID, principal, permissionGroupID, func(rg cloudflare.ResourceGroup) bool {
+		return resourceGroupCoversAccount(rg, accountID)
+	})
+}
+
+func permissionGroupBuilder(client *cloudflare.API, accountId, emailId string) *permissionGroupResourceType {
+	return &permissionGroupResourceType{
+		resourceType: resourceTypePermissionGroup,
+		client:       client,
+		accountId:    accountId,
+		emailId:      emailId,
+	}
+}
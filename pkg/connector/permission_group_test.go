@@ -0,0 +1,34 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionGroupScopes(t *testing.T) {
+	zoneGroup := cloudflare.PermissionGroup{ID: "pg-1", Meta: map[string]string{"scopes": "com.cloudflare.api.account.zone"}}
+	accountGroup := cloudflare.PermissionGroup{ID: "pg-2", Meta: map[string]string{"scopes": "com.cloudflare.api.account"}}
+	unscoped := cloudflare.PermissionGroup{ID: "pg-3"}
+
+	assert.True(t, isZonePermissionGroup(zoneGroup))
+	assert.False(t, permissionGroupHasScope(zoneGroup, accountScopeKey))
+	assert.False(t, isZonePermissionGroup(accountGroup))
+	assert.True(t, permissionGroupHasScope(accountGroup, accountScopeKey))
+	assert.True(t, isZonePermissionGroup(unscoped))
+	assert.True(t, permissionGroupHasScope(unscoped, accountScopeKey))
+}
+
+func TestResourceGroupCoversAccount(t *testing.T) {
+	assert.True(t, resourceGroupCoversAccount(cloudflare.NewResourceGroupForAccount(cloudflare.Account{ID: "acct-1"}), "acct-1"))
+	assert.False(t, resourceGroupCoversAccount(cloudflare.NewResourceGroupForAccount(cloudflare.Account{ID: "acct-2"}), "acct-1"))
+
+	zonesOnly := cloudflare.ResourceGroup{
+		Scope: cloudflare.Scope{
+			Key:          "com.cloudflare.api.account.acct-1",
+			ScopeObjects: []cloudflare.ScopeObject{{Key: "com.cloudflare.api.account.zone.zone-1"}},
+		},
+	}
+	assert.False(t, resourceGroupCoversAccount(zonesOnly, "acct-1"))
+}
@@ -0,0 +1,385 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	policyAccessAllow = "allow"
+	policyAccessDeny  = "deny"
+	// policyIDSeparator joins the permission group and resource group halves of a policy resource ID.
+	policyIDSeparator = ":"
+)
+
+type policyResourceType struct {
+	resourceType *v2.ResourceType
+	client       *cloudflare.API
+	accountId    string
+}
+
+func (o *policyResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+// resourceGroupKey identifies a resource group inside a policy. Resource groups created
+// through the IAM API carry an ID; inline groups built from a scope (the form cloudflare-go
+// produces with NewResourceGroupForZone/NewResourceGroupForAccount) only have a scope key.
+func resourceGroupKey(rg cloudflare.ResourceGroup) string {
+	if rg.ID != "" {
+		return rg.ID
+	}
+
+	return rg.Scope.Key
+}
+
+func resourceGroupName(rg cloudflare.ResourceGroup) string {
+	if rg.Name != "" {
+		return rg.Name
+	}
+
+	return resourceGroupKey(rg)
+}
+
+// policyResourceID builds the ID of a permission group × resource group combination.
+// Member policies have per-member IDs, so the combination is what stays stable across members.
+func policyResourceID(permissionGroupID, resourceGroupKey string) string {
+	return permissionGroupID + policyIDSeparator + resourceGroupKey
+}
+
+// policyBinding is a single permission group applied to a single resource group.
+type policyBinding struct {
+	permissionGroup cloudflare.PermissionGroup
+	resourceGroup   cloudflare.ResourceGroup
+}
+
+func (b policyBinding) id() string {
+	return policyResourceID(b.permissionGroup.ID, resourceGroupKey(b.resourceGroup))
+}
+
+// policyBindings expands a member policy into its permission group × resource group combinations.
+func policyBindings(policy cloudflare.Policy) []policyBinding {
+	var rv []policyBinding
+	for _, pg := range policy.PermissionGroups {
+		for _, rg := range policy.ResourceGroups {
+			rv = append(rv, policyBinding{permissionGroup: pg, resourceGroup: rg})
+		}
+	}
+
+	return rv
+}
+
+// policyResource builds the resource for one binding. resourceID is the account-scoped form of
+// policyResourceID, since permission group and resource group IDs repeat across accounts.
+func policyResource(pg cloudflare.PermissionGroup, rg cloudflare.ResourceGroup, resourceID string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	scopeObjects := make([]string, 0, len(rg.Scope.ScopeObjects))
+	for _, obj := range rg.Scope.ScopeObjects {
+		scopeObjects = append(scopeObjects, obj.Key)
+	}
+
+	profile := map[string]interface{}{
+		"permission_group_id":   pg.ID,
+		"permission_group_name": pg.Name,
+		"resource_group_id":     rg.ID,
+		"resource_group_name":   resourceGroupName(rg),
+		"scope_key":             rg.Scope.Key,
+		"scope_objects":         strings.Join(scopeObjects, ","),
+	}
+
+	pgName := pg.Name
+	if pgName == "" {
+		pgName = pg.ID
+	}
+
+	return rs.NewResource(
+		fmt.Sprintf("%s on %s", pgName, resourceGroupName(rg)),
+		resourceTypePolicy,
+		resourceID,
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithResourceProfile(profile),
+	)
+}
+
+// List returns one resource per distinct permission group × resource group combination found
+// across all account members' policies. Combinations repeat across members, so the full member
+// list is read in one pass and de-duplicated rather than paginated.
+func (o *policyResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	members, err := accountMembersSnapshot(ctx, o.client, opts.Session, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seen := make(map[string]struct{})
+	var rv []*v2.Resource
+	for _, member := range members {
+		for _, policy := range member.Policies {
+			for _, binding := range policyBindings(policy) {
+				if _, ok := seen[binding.id()]; ok {
+					continue
+				}
+				seen[binding.id()] = struct{}{}
+
+				resource, err := policyResource(
+					binding.permissionGroup,
+					binding.resourceGroup,
+					accountScopedID(o.accountId, accountID, binding.id()),
+					parentResourceID,
+				)
+				if err != nil {
+					return nil, nil, err
+				}
+				rv = append(rv, resource)
+			}
+		}
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// Entitlements returns the allow and deny entitlements of the combination. A combination is only
+// a slice of a member's policy set, so both are read-only; grant permission groups or zone roles
+// to change what a member can do.
+func (o *policyResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
+	rv := []*v2.Entitlement{
+		ent.NewPermissionEntitlement(
+			resource,
+			policyAccessAllow,
+			ent.WithGrantableTo(resourceTypeUser),
+			ent.WithDisplayName(fmt.Sprintf("%s Allowed", resource.DisplayName)),
+			ent.WithDescription(fmt.Sprintf("Is allowed %s by a Cloudflare member policy", resource.DisplayName)),
+			ent.WithAnnotation(&v2.EntitlementImmutable{}),
+		),
+		ent.NewPermissionEntitlement(
+			resource,
+			policyAccessDeny,
+			ent.WithGrantableTo(resourceTypeUser),
+			ent.WithDisplayName(fmt.Sprintf("%s Denied", resource.DisplayName)),
+			ent.WithDescription(fmt.Sprintf("Is denied %s by a Cloudflare member policy", resource.DisplayName)),
+			ent.WithAnnotation(&v2.EntitlementImmutable{}),
+		),
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// Grants reads the sync's shared member snapshot, so every grant is returned in one page.
+func (o *policyResourceType) Grants(ctx context.Context, resource *v2.Resource, opts rs.SyncOpAttrs) ([]*v2.Grant, *rs.SyncOpResults, error) {
+	accountID, policyID := parseAccountScopedID(o.accountId, resource.Id.Resource)
+	members, err := accountMembersSnapshot(ctx, o.client, opts.Session, accountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: could not retrieve account members: %w", err)
+	}
+
+	var rv []*v2.Grant
+	for _, member := range members {
+		// Pending invitations have no User.ID yet.
+		if member.User.ID == "" {
+			continue
+		}
+
+		granted := make(map[string]bool)
+		for _, policy := range member.Policies {
+			access := strings.ToLower(policy.Access)
+			if access != policyAccessAllow && access != policyAccessDeny {
+				continue
+			}
+			for _, binding := range policyBindings(policy) {
+				if binding.id() == policyID {
+					granted[access] = true
+				}
+			}
+		}
+		if len(granted) == 0 {
+			continue
+		}
+
+		ur, err := userResource(member, accountScopedID(o.accountId, accountID, member.User.ID), accountResourceID(accountID))
+		if err != nil {
+			return nil, nil, wrapError(err, "failed to create user resource")
+		}
+		for _, access := range []string{policyAccessAllow, policyAccessDeny} {
+			if granted[access] {
+				rv = append(rv, grant.NewGrant(resource, access, ur.Id, grant.WithAnnotation(&v2.GrantImmutable{})))
+			}
+		}
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+func policyBuilder(client *cloudflare.API, accountId string) *policyResourceType {
+	return &policyResourceType{
+		resourceType: resourceTypePolicy,
+		client:       client,
+		accountId:    accountId,
+	}
+}
+
+// hasAllowBinding reports whether any allow policy grants permissionGroupID on a resource
+// group accepted by covers.
+func hasAllowBinding(policies []cloudflare.Policy, permissionGroupID string, covers func(cloudflare.ResourceGroup) bool) bool {
+	for _, policy := range policies {
+		if !strings.EqualFold(policy.Access, policyAccessAllow) {
+			continue
+		}
+		for _, binding := range policyBindings(policy) {
+			if binding.permissionGroup.ID == permissionGroupID && covers(binding.resourceGroup) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// withoutAllowBinding removes permissionGroupID from every allow policy on resource groups
+// accepted by covers, leaving the rest of each policy intact. A policy that also binds other
+// permission groups keeps them, and a policy that also spans other resource groups is split so
+// the permission group stays bound to those. It reports whether anything was removed.
+func withoutAllowBinding(policies []cloudflare.Policy, permissionGroupID string, covers func(cloudflare.ResourceGroup) bool) ([]cloudflare.Policy, bool) {
+	removed := false
+	rv := make([]cloudflare.Policy, 0, len(policies))
+	for _, policy := range policies {
+		pgIndex := slices.IndexFunc(policy.PermissionGroups, func(pg cloudflare.PermissionGroup) bool {
+			return pg.ID == permissionGroupID
+		})
+		if !strings.EqualFold(policy.Access, policyAccessAllow) || pgIndex == NF {
+			rv = append(rv, policy)
+			continue
+		}
+
+		var coveredGroups, otherGroups []cloudflare.ResourceGroup
+		for _, rg := range policy.ResourceGroups {
+			if covers(rg) {
+				coveredGroups = append(coveredGroups, rg)
+			} else {
+				otherGroups = append(otherGroups, rg)
+			}
+		}
+		if len(coveredGroups) == 0 {
+			rv = append(rv, policy)
+			continue
+		}
+		removed = true
+
+		otherPermissionGroups := slices.Delete(slices.Clone(policy.PermissionGroups), pgIndex, pgIndex+1)
+		if len(otherPermissionGroups) > 0 {
+			remaining := policy
+			remaining.PermissionGroups = otherPermissionGroups
+			rv = append(rv, remaining)
+		}
+		if len(otherGroups) > 0 {
+			rv = append(rv, cloudflare.Policy{
+				Access:           policyAccessAllow,
+				PermissionGroups: []cloudflare.PermissionGroup{policy.PermissionGroups[pgIndex]},
+				ResourceGroups:   otherGroups,
+			})
+		}
+	}
+
+	return rv, removed
+}
+
+// grantMemberPolicy adds an allow policy binding permissionGroupID to rg for the principal's
+// membership. covers recognises existing resource groups that already give the same access, in
+// which case nothing is written. The member update replaces the full policy set, so the member's
+// existing policies are sent back unchanged alongside the new one.
+func grantMemberPolicy(
+	ctx context.Context,
+	client *cloudflare.API,
+	emailId, accountID string,
+	principal *v2.Resource,
+	permissionGroupID string,
+	rg cloudflare.ResourceGroup,
+	covers func(cloudflare.ResourceGroup) bool,
+) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	memberId, err := memberIDForPrincipal(ctx, client, accountID, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := getAccountMember(ctx, client, emailId, accountID, memberId)
+	if err != nil {
+		return nil, fmt.Errorf("baton-cloudflare: failed to get account member for grant: %w", err)
+	}
+
+	if hasAllowBinding(member.Result.Policies, permissionGroupID, covers) {
+		l.Warn(
+			"baton-cloudflare: member already has a policy for this permission group",
+			zap.String("principal_id", principal.Id.String()),
+			zap.String("permission_group_id", permissionGroupID),
+			zap.String("resource_group", resourceGroupKey(rg)),
+		)
+		return annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+
+	policies := append(slices.Clone(member.Result.Policies), cloudflare.Policy{
+		Access:           policyAccessAllow,
+		PermissionGroups: []cloudflare.PermissionGroup{{ID: permissionGroupID}},
+		ResourceGroups:   []cloudflare.ResourceGroup{rg},
+	})
+	_, err = updateAccountMemberPolicies(ctx, client, emailId, accountID, memberId, policies)
+	err = getError(err)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// revokeMemberPolicy removes permissionGroupID from the principal's allow policies on resource
+// groups accepted by covers.
+func revokeMemberPolicy(
+	ctx context.Context,
+	client *cloudflare.API,
+	emailId, accountID string,
+	principal *v2.Resource,
+	permissionGroupID string,
+	covers func(cloudflare.ResourceGroup) bool,
+) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	memberId, err := memberIDForPrincipal(ctx, client, accountID, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := getAccountMember(ctx, client, emailId, accountID, memberId)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, removed := withoutAllowBinding(member.Result.Policies, permissionGroupID, covers)
+	if !removed {
+		l.Warn(
+			"baton-cloudflare: member has no policy for this permission group",
+			zap.String("principal_id", principal.Id.String()),
+			zap.String("permission_group_id", permissionGroupID),
+		)
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	_, err = updateAccountMemberPolicies(ctx, client, emailId, accountID, memberId, policies)
+	err = getError(err)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,136 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyResource(t *testing.T) {
+	pg := cloudflare.PermissionGroup{ID: "pg-1", Name: "DNS Write"}
+	rg := cloudflare.ResourceGroup{
+		ID:   "rg-1",
+		Name: "Production zones",
+		Scope: cloudflare.Scope{
+			Key:          "com.cloudflare.api.account.acct-1",
+			ScopeObjects: []cloudflare.ScopeObject{{Key: "com.cloudflare.api.account.zone.zone-1"}},
+		},
+	}
+
+	resource, err := policyResource(pg, rg, policyResourceID(pg.ID, resourceGroupKey(rg)), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "pg-1:rg-1", resource.GetId().GetResource())
+	assert.Equal(t, resourceTypePolicy.GetId(), resource.GetId().GetResourceType())
+	assert.Equal(t, "DNS Write on Production zones", resource.GetDisplayName())
+
+	scopeObjects, found := rs.GetProfileStringValue(resource.GetProfile(), "scope_objects")
+	require.True(t, found)
+	assert.Equal(t, "com.cloudflare.api.account.zone.zone-1", scopeObjects)
+}
+
+// Inline resource groups, as built by cloudflare-go's NewResourceGroup helpers, have no ID;
+// the scope key has to stand in for it so the policy resource ID stays stable.
+func TestPolicyResourceInlineResourceGroup(t *testing.T) {
+	pg := cloudflare.PermissionGroup{ID: "pg-1", Name: "DNS Write"}
+	rg := cloudflare.NewResourceGroup("com.cloudflare.api.account.zone.zone-1")
+
+	resource, err := policyResource(pg, rg, policyResourceID(pg.ID, resourceGroupKey(rg)), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "pg-1:com.cloudflare.api.account.zone.zone-1", resource.GetId().GetResource())
+}
+
+func TestPolicyBindings(t *testing.T) {
+	policy := cloudflare.Policy{
+		Access: "allow",
+		PermissionGroups: []cloudflare.PermissionGroup{
+			{ID: "pg-1"},
+			{ID: "pg-2"},
+		},
+		ResourceGroups: []cloudflare.ResourceGroup{
+			{ID: "rg-1"},
+			{ID: "rg-2"},
+		},
+	}
+
+	var ids []string
+	for _, binding := range policyBindings(policy) {
+		ids = append(ids, binding.id())
+	}
+	assert.ElementsMatch(t, []string{"pg-1:rg-1", "pg-1:rg-2", "pg-2:rg-1", "pg-2:rg-2"}, ids)
+}
+
+func TestWithoutAllowBinding(t *testing.T) {
+	zoneGroup := cloudflare.NewResourceGroupForZone(cloudflare.Zone{ID: "zone-1"})
+	otherZoneGroup := cloudflare.NewResourceGroupForZone(cloudflare.Zone{ID: "zone-2"})
+	coversZone := func(rg cloudflare.ResourceGroup) bool {
+		return resourceGroupCoversZone(rg, "zone-1")
+	}
+
+	policies := []cloudflare.Policy{
+		{
+			ID:               "policy-1",
+			Access:           "allow",
+			PermissionGroups: []cloudflare.PermissionGroup{{ID: "dns"}, {ID: "firewall"}},
+			ResourceGroups:   []cloudflare.ResourceGroup{zoneGroup, otherZoneGroup},
+		},
+		{
+			ID:               "policy-2",
+			Access:           "deny",
+			PermissionGroups: []cloudflare.PermissionGroup{{ID: "dns"}},
+			ResourceGroups:   []cloudflare.ResourceGroup{zoneGroup},
+		},
+	}
+	require.True(t, hasAllowBinding(policies, "dns", coversZone))
+
+	updated, removed := withoutAllowBinding(policies, "dns", coversZone)
+	require.True(t, removed)
+	assert.False(t, hasAllowBinding(updated, "dns", coversZone))
+
+	// firewall keeps both zones, dns keeps the other zone, and the deny policy is untouched.
+	assert.True(t, hasAllowBinding(updated, "firewall", coversZone))
+	assert.True(t, hasAllowBinding(updated, "dns", func(rg cloudflare.ResourceGroup) bool {
+		return resourceGroupCoversZone(rg, "zone-2")
+	}))
+	assert.Len(t, updated, 3)
+	assert.Equal(t, "policy-2", updated[2].ID)
+
+	// The caller's policies must not be mutated.
+	assert.Len(t, policies[0].PermissionGroups, 2)
+}
+
+func TestWithoutAllowBindingNotPresent(t *testing.T) {
+	policies := []cloudflare.Policy{
+		{
+			Access:           "allow",
+			PermissionGroups: []cloudflare.PermissionGroup{{ID: "dns"}},
+			ResourceGroups:   []cloudflare.ResourceGroup{cloudflare.NewResourceGroupForZone(cloudflare.Zone{ID: "zone-2"})},
+		},
+	}
+
+	updated, removed := withoutAllowBinding(policies, "dns", func(rg cloudflare.ResourceGroup) bool {
+		return resourceGroupCoversZone(rg, "zone-1")
+	})
+	assert.False(t, removed)
+	assert.Equal(t, policies, updated)
+}
+
+// Policy combinations have no Grant or Revoke, so their entitlements must not be requestable.
+func TestPolicyEntitlementsImmutable(t *testing.T) {
+	resource, err := policyResource(cloudflare.PermissionGroup{ID: "pg-1"}, cloudflare.ResourceGroup{ID: "rg-1"}, "pg-1:rg-1", nil)
+	require.NoError(t, err)
+
+	entitlements, _, err := policyBuilder(nil, "acct-1").Entitlements(context.Background(), resource, rs.SyncOpAttrs{})
+	require.NoError(t, err)
+	require.Len(t, entitlements, 2)
+	for _, entitlement := range entitlements {
+		assert.Equal(t, v2.Entitlement_PURPOSE_VALUE_PERMISSION, entitlement.GetPurpose())
+		annos := annotations.Annotations(entitlement.GetAnnotations())
+		assert.True(t, annos.Contains(&v2.EntitlementImmutable{}))
+	}
+}
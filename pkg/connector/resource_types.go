@@ -5,6 +5,17 @@ import (
 )
 
 var (
+	resourceTypeAccount = &v2.ResourceType{
+		Id:          "account",
+		DisplayName: "Account",
+		Annotations: buildAnnotations(
+			capabilityPermissions(
+				"Account Settings: Read",
+				"Access: Users:Read",
+				"Zero Trust: Seats:Edit",
+			),
+		),
+	}
 	resourceTypeUser = &v2.ResourceType{
 		Id:          "user",
 		DisplayName: "User",
@@ -48,6 +59,146 @@ var (
 			),
 		),
 	}
+	resourceTypeGroup = &v2.ResourceType{
+		Id:          "group",
+		DisplayName: "Group",
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_GROUP,
+		},
+		Annotations: buildAnnotations(
+			capabilityPermissions(
+				"Account Settings: Read",
+				"Account Settings: Edit",
+			),
+		),
+	}
+	resourceTypeAccessGroup = &v2.ResourceType{
+		Id:          "access_group",
+		DisplayName: "Access Group",
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_GROUP,
+		},
+		Annotations: buildAnnotations(
+			capabilityPermissions(
+				"Access: Organizations, Identity Providers and Groups:Read",
+				"Access: Organizations, Identity Providers and Groups:Edit",
+			),
+		),
+	}
+	resourceTypeAccessApplication = &v2.ResourceType{
+		Id:          "app",
+		DisplayName: "Access Application",
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_APP,
+		},
+		Annotations: buildAnnotations(
+			capabilityPermissions(
+				"Access: Apps and Policies:Read",
+			),
+		),
+	}
+	resourceTypeAccessPolicy = &v2.ResourceType{
+		Id:          "access_policy",
+		DisplayName: "Access Policy",
+		Annotations: buildAnnotations(
+			capabilityPermissions(
+				"Access: Apps and Policies:Read",
+			),
+		),
+	}
+	resourceTypeAccessUser = &v2.ResourceType{
+		Id:          "access_user",
+		DisplayName: "Access User",
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_USER,
+		},
+		Annotations: buildAnnotations(
+			capabilityPermissions(
+				"Access: Users:Read",
+			),
+			&v2.SkipEntitlementsAndGrants{},
+		),
+	}
+	resourceTypeAccessIdentityProvider = &v2.ResourceType{
+		Id:          "access_identity_provider",
+		DisplayName: "Access Identity Provider",
+		Annotations: buildAnnotations(
+			capabilityPermissions(
+				"Access: Organizations, Identity Providers and Groups:Read",
+			),
+			&v2.SkipEntitlementsAndGrants{},
+		),
+	}
+	resourceTypeAccessServiceToken = &v2.ResourceType{
+		Id:          "access_service_token",
+		DisplayName: "Access Service Token",
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_SECRET,
+		},
+		Annotations: buildAnnotations(
+			capabilityPermissions(
+				"Access: Service Tokens:Read",
+				"Access: Service Tokens:Edit",
+			),
+			&v2.SkipEntitlementsAndGrants{},
+		),
+	}
+	resourceTypeDevice = &v2.ResourceType{
+		Id:          "device",
+		DisplayName: "Device",
+		Annotations: buildAnnotations(
+			capabilityPermissions(
+				"Zero Trust:Read",
+				"Zero Trust:Edit",
+			),
+		),
+	}
+	resourceTypePolicy = &v2.ResourceType{
+		Id:          "policy",
+		DisplayName: "Policy",
+		Annotations: buildAnnotations(
+			capabilityPermissions(
+				"Account Settings: Read",
+			),
+		),
+	}
+	resourceTypePermissionGroup = &v2.ResourceType{
+		Id:          "permission_group",
+		DisplayName: "Permission Group",
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_ROLE,
+		},
+		Annotations: buildAnnotations(
+			capabilityPermissions(
+				"Account Settings: Read",
+				"Account Settings: Edit",
+			),
+		),
+	}
+	resourceTypeZone = &v2.ResourceType{
+		Id:          "zone",
+		DisplayName: "Zone",
+		Annotations: buildAnnotations(
+			capabilityPermissions(
+				"Zone: Read",
+			),
+			&v2.SkipEntitlementsAndGrants{},
+		),
+	}
+	resourceTypeZoneRole = &v2.ResourceType{
+		Id:          "zone_role",
+		DisplayName: "Zone Role",
+		Traits: []v2.ResourceType_Trait{
+			v2.ResourceType_TRAIT_ROLE,
+		},
+		Annotations: buildAnnotations(
+			capabilityPermissions(
+				"Zone: Read",
+				"Account Settings: Read",
+				"Account Settings: Edit",
+			),
+		),
+	}
 	resourceTypeAPIToken = &v2.ResourceType{
 		Id:          "api_token",
 		DisplayName: "API Token",
@@ -57,8 +208,18 @@ var (
 		Annotations: buildAnnotations(
 			capabilityPermissions(
 				"Account API Tokens:Read",
+				"Account API Tokens:Edit",
 			),
 			&v2.SkipEntitlementsAndGrants{},
 		),
 	}
+	resourceTypeTokenPermissionGroup = &v2.ResourceType{
+		Id:          "token_permission_group",
+		DisplayName: "API Token Permission Group",
+		Annotations: buildAnnotations(
+			capabilityPermissions(
+				"Account API Tokens:Read",
+			),
+		),
+	}
 )
@@ -4,10 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
 	"slices"
+	"strings"
 
 	"github.com/cloudflare/cloudflare-go"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
@@ -15,6 +13,7 @@ import (
 	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
 	"github.com/conductorone/baton-sdk/pkg/types/grant"
 	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"go.uber.org/zap"
@@ -22,12 +21,19 @@ import (
 
 const (
 	roleMemberEntitlement = "member"
-	// The list custom roles endpoint does not return the super admin role, so we are manually adding it with Cloudflares super admin role ID.
+	// The list custom roles endpoint does not return the super admin role. It is discovered from the
+	// roles members hold; this well-known ID is only used when discovery finds no super admin role.
 	SuperAdminRoleId    = "33666b9c79b9a5273fc7344ff42f953d"
+	superAdminRoleName  = "Super Administrator - All Privileges"
 	errMissingAccountID = "required missing account ID"
 	XAuthEmailHeaderKey = "X-Auth-Email"
 	XAuthKeyHeaderKey   = "X-Auth-Key"
 	NF                  = -1
+
+	rolePermissionRead        = "read"
+	rolePermissionEdit        = "edit"
+	rolePermissionsProfileKey = "permissions"
+	rolePrivilegedProfileKey  = "privileged"
 )
 
 var ErrMissingAccountID = errors.New(errMissingAccountID)
@@ -44,20 +50,118 @@ func (o *roleResourceType) ResourceType(_ context.Context) *v2.ResourceType {
 	return o.resourceType
 }
 
-// getRoleResource creates a new connector resource for a Zendesk role.
-func roleResource(role cloudflare.AccountRole, resourceTypeRole *v2.ResourceType, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+// rolePermissionSlugs flattens a role's permission matrix into sorted "area:read" and
+// "area:edit" slugs, one per flag the role grants.
+func rolePermissionSlugs(permissions map[string]cloudflare.AccountRolePermission) []string {
+	areas := make([]string, 0, len(permissions))
+	for area := range permissions {
+		areas = append(areas, area)
+	}
+	slices.Sort(areas)
+
+	var rv []string
+	for _, area := range areas {
+		if permissions[area].Read {
+			rv = append(rv, area+":"+rolePermissionRead)
+		}
+		if permissions[area].Edit {
+			rv = append(rv, area+":"+rolePermissionEdit)
+		}
+	}
+
+	return rv
+}
+
+// rolePermissionMatrix is the profile form of a role's permissions: each area maps to its read
+// and edit flags, so areas the role can only read stay distinguishable from ones it can edit.
+func rolePermissionMatrix(permissions map[string]cloudflare.AccountRolePermission) map[string]interface{} {
+	rv := make(map[string]interface{}, len(permissions))
+	for area, permission := range permissions {
+		rv[area] = map[string]interface{}{
+			rolePermissionRead: permission.Read,
+			rolePermissionEdit: permission.Edit,
+		}
+	}
+
+	return rv
+}
+
+// rolePermissionsFromProfile reads back the permission matrix written by roleResource.
+func rolePermissionsFromProfile(resource *v2.Resource) map[string]cloudflare.AccountRolePermission {
+	matrix := resource.GetProfile().GetFields()[rolePermissionsProfileKey].GetStructValue()
+
+	rv := make(map[string]cloudflare.AccountRolePermission, len(matrix.GetFields()))
+	for area, value := range matrix.GetFields() {
+		flags := value.GetStructValue().GetFields()
+		rv[area] = cloudflare.AccountRolePermission{
+			Read: flags[rolePermissionRead].GetBoolValue(),
+			Edit: flags[rolePermissionEdit].GetBoolValue(),
+		}
+	}
+
+	return rv
+}
+
+// rolePermissionSlugsFromProfile flattens the permission matrix in the role's profile into
+// permission slugs.
+func rolePermissionSlugsFromProfile(resource *v2.Resource) []string {
+	return rolePermissionSlugs(rolePermissionsFromProfile(resource))
+}
+
+// isRoleMemberEntitlement reports whether the entitlement is the role's provisionable
+// member entitlement rather than one of its read-only permission entitlements.
+func isRoleMemberEntitlement(entitlement *v2.Entitlement) bool {
+	return strings.HasSuffix(entitlement.GetId(), ":"+roleMemberEntitlement)
+}
+
+// isSuperAdminRole reports whether a role is a Super Administrator role, which grants every
+// permission on the account and cannot be listed through the account roles endpoint. Roles are
+// recognised by the well-known ID or by permission data that grants read and edit on every area,
+// never by name, since custom roles can be named anything.
+func isSuperAdminRole(role cloudflare.AccountRole) bool {
+	if role.ID == SuperAdminRoleId {
+		return true
+	}
+	if len(role.Permissions) == 0 {
+		return false
+	}
+	for _, permission := range role.Permissions {
+		if !permission.Read || !permission.Edit {
+			return false
+		}
+	}
+
+	return true
+}
+
+// roleResource creates a new connector resource for a Cloudflare account role.
+// Role IDs repeat across accounts, so resourceID is the account-scoped form of role.ID.
+// Super Administrator roles are flagged as privileged in the profile and carry a critical risk factor.
+func roleResource(role cloudflare.AccountRole, resourceTypeRole *v2.ResourceType, resourceID string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	privileged := isSuperAdminRole(role)
 	profile := map[string]interface{}{
-		"role_id":   role.ID,
-		"role_name": role.Name,
+		"role_id":                 role.ID,
+		"role_name":               role.Name,
+		rolePermissionsProfileKey: rolePermissionMatrix(role.Permissions),
+		rolePrivilegedProfileKey:  privileged,
+	}
+
+	opts := []rs.ResourceOption{
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithResourceProfile(profile),
+	}
+	if privileged {
+		opts = append(opts, rs.WithAnnotation(
+			rs.NewRiskFactor("Super Administrator role: all privileges on the account", v2.RiskFactor_SEVERITY_CRITICAL),
+		))
 	}
 
 	ret, err := rs.NewRoleResource(
 		role.Name,
 		resourceTypeRole,
-		role.ID,
+		resourceID,
 		nil,
-		rs.WithParentResourceID(parentResourceID),
-		rs.WithResourceProfile(profile),
+		opts...,
 	)
 	if err != nil {
 		return nil, err
@@ -66,40 +170,94 @@ func roleResource(role cloudflare.AccountRole, resourceTypeRole *v2.ResourceType
 	return ret, nil
 }
 
-func (o *roleResourceType) List(ctx context.Context, _ *v2.ResourceId, _ rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+func (o *roleResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
 	// Empty params causes ListAccountRoles to auto paginate and return all account roles
 	params := cloudflare.ListAccountRolesParams{}
-	roles, err := o.client.ListAccountRoles(ctx, cloudflare.AccountIdentifier(o.accountId), params)
+	roles, err := o.client.ListAccountRoles(ctx, cloudflare.AccountIdentifier(accountID), params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hiddenRoles, err := o.discoverHiddenRoles(ctx, opts.Session, accountID, roles)
 	if err != nil {
 		return nil, nil, err
 	}
+	roles = append(roles, hiddenRoles...)
+	if !slices.ContainsFunc(roles, isSuperAdminRole) {
+		roles = append(roles, cloudflare.AccountRole{
+			ID:   SuperAdminRoleId,
+			Name: superAdminRoleName,
+		})
+	}
+
 	rv := make([]*v2.Resource, 0, len(roles))
 	for _, role := range roles {
-		roleResource, err := roleResource(role, resourceTypeRole, nil)
+		roleResource, err := roleResource(role, resourceTypeRole, accountScopedID(o.accountId, accountID, role.ID), parentResourceID)
 		if err != nil {
 			return nil, nil, err
 		}
 		rv = append(rv, roleResource)
 	}
 
-	adminRoleResource, err := roleResource(cloudflare.AccountRole{
-		ID:   SuperAdminRoleId,
-		Name: "Super Administrator - All Privileges",
-	}, resourceTypeRole, nil)
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// discoverHiddenRoles returns roles held by account members that the list roles endpoint does not
+// return, such as Super Administrator. Each is looked up with GetAccountRole for its full
+// definition; the copy embedded in the member is used if the lookup fails.
+func (o *roleResourceType) discoverHiddenRoles(ctx context.Context, ss sessions.SessionStore, accountID string, listed []cloudflare.AccountRole) ([]cloudflare.AccountRole, error) {
+	l := ctxzap.Extract(ctx)
+	members, err := accountMembersSnapshot(ctx, o.client, ss, accountID)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	rv = append(rv, adminRoleResource)
 
-	return rv, &rs.SyncOpResults{}, nil
+	seen := make(map[string]struct{}, len(listed))
+	for _, role := range listed {
+		seen[role.ID] = struct{}{}
+	}
+
+	var rv []cloudflare.AccountRole
+	for _, member := range members {
+		for _, memberRole := range member.Roles {
+			if _, ok := seen[memberRole.ID]; ok {
+				continue
+			}
+			seen[memberRole.ID] = struct{}{}
+
+			role, err := o.client.GetAccountRole(ctx, cloudflare.AccountIdentifier(accountID), memberRole.ID)
+			if err != nil {
+				l.Debug(
+					"baton-cloudflare: could not retrieve role, using member role data",
+					zap.String("role_id", memberRole.ID),
+					zap.Error(err),
+				)
+				role = memberRole
+			}
+			if role.Name == "" && role.ID == SuperAdminRoleId {
+				role.Name = superAdminRoleName
+			}
+			rv = append(rv, role)
+		}
+	}
+
+	return rv, nil
 }
 
+// Entitlements returns the role's member entitlement, which is provisioned by assigning the role,
+// and one read-only entitlement per permission the role grants, such as "dns:edit", so access can
+// be reviewed across roles.
 func (r *roleResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
 	rv := []*v2.Entitlement{
 		ent.NewAssignmentEntitlement(
 			resource,
 			roleMemberEntitlement,
-			ent.WithGrantableTo(resourceTypeUser),
+			ent.WithGrantableTo(resourceTypeUser, resourceTypeInvitation),
 			ent.WithDisplayName(
 				fmt.Sprintf("%s Member Role", resource.DisplayName),
 			),
@@ -109,79 +267,41 @@ func (r *roleResourceType) Entitlements(_ context.Context, resource *v2.Resource
 		),
 	}
 
+	for _, permission := range rolePermissionSlugsFromProfile(resource) {
+		rv = append(rv, ent.NewPermissionEntitlement(
+			resource,
+			permission,
+			ent.WithGrantableTo(resourceTypeUser, resourceTypeInvitation),
+			ent.WithDisplayName(
+				fmt.Sprintf("%s %s", resource.DisplayName, permission),
+			),
+			ent.WithDescription(
+				fmt.Sprintf("Has %s permission in Cloudflare through the %s role", permission, resource.DisplayName),
+			),
+			ent.WithAnnotation(&v2.EntitlementImmutable{}),
+		))
+	}
+
 	return rv, &rs.SyncOpResults{}, nil
 }
 
 // GetAccountMember returns an account member.
 func (r *roleResourceType) GetAccountMember(ctx context.Context, accountID string, memberID string) (*cloudflare.AccountMemberDetailResponse, error) {
-	var accountMemberListResponse = &cloudflare.AccountMemberDetailResponse{}
-	httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
-	if err != nil {
-		return nil, err
-	}
-
-	if accountID == "" {
-		return &cloudflare.AccountMemberDetailResponse{}, ErrMissingAccountID
-	}
-
-	r.httpClient = uhttp.NewBaseHttpClient(httpClient)
-	endpointUrl := fmt.Sprintf("%s/accounts/%s/members/%s", r.client.BaseURL, accountID, memberID)
-	uri, err := url.Parse(endpointUrl)
-	if err != nil {
-		return nil, err
-	}
-
-	opts := []uhttp.RequestOption{
-		uhttp.WithAcceptJSONHeader(),
-	}
-	if r.client.APIToken != "" {
-		opts = append(opts, uhttp.WithBearerToken(r.client.APIToken))
-	}
-	if r.emailId != "" {
-		opts = append(opts, uhttp.WithHeader(XAuthEmailHeaderKey, r.emailId))
-	}
-	if r.client.APIKey != "" {
-		opts = append(opts, uhttp.WithHeader(XAuthKeyHeaderKey, r.client.APIKey))
-	}
-	req, err := r.httpClient.NewRequest(ctx,
-		http.MethodGet,
-		uri,
-		opts...,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := r.httpClient.Do(req, uhttp.WithJSONResponse(&accountMemberListResponse))
-	if err != nil {
-		return nil, fmt.Errorf("baton-cloudflare: failed to get account member: %w", err)
-	}
-
-	defer resp.Body.Close()
-	return accountMemberListResponse, err
+	return getAccountMember(ctx, r.client, r.emailId, accountID, memberID)
 }
 
+// Grants emits the role's grants from the sync's shared member snapshot, so the account members
+// are fetched once per sync rather than once per role, and every grant is returned in one page.
 func (r *roleResourceType) Grants(ctx context.Context, resource *v2.Resource, opts rs.SyncOpAttrs) ([]*v2.Grant, *rs.SyncOpResults, error) {
 	var rv []*v2.Grant
-	page, err := convertPageToken(opts.PageToken.Token)
-	if err != nil {
-		return nil, nil, fmt.Errorf("baton-cloudflare: invalid page token error")
-	}
-
-	pageOpts := cloudflare.PaginationOptions{Page: page}
-	users, resp, err := r.client.AccountMembers(ctx, r.accountId, pageOpts)
+	accountID, roleId := parseAccountScopedID(r.accountId, resource.Id.Resource)
+	users, err := accountMembersSnapshot(ctx, r.client, opts.Session, accountID)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	roleId := resource.Id.Resource
-	nextPage := convertNextPageToken(resp.Page, len(users))
+	permissions := rolePermissionSlugsFromProfile(resource)
 	for _, user := range users {
-		// Pending invitations have no User.ID yet.
-		if user.User.ID == "" {
-			continue
-		}
-
 		userPos := slices.IndexFunc(user.Roles, func(r cloudflare.AccountRole) bool {
 			return r.ID == roleId
 		})
@@ -189,61 +309,67 @@ func (r *roleResourceType) Grants(ctx context.Context, resource *v2.Resource, op
 			continue
 		}
 
-		accUser := cloudflare.AccountMember{
-			User: cloudflare.AccountMemberUserDetails{
-				ID:        user.User.ID,
-				FirstName: user.User.FirstName,
-				LastName:  user.User.LastName,
-				Email:     user.User.Email,
-			},
-		}
-		ur, err := userResource(accUser)
-		if err != nil {
-			return nil, nil, wrapError(err, "failed to create user resource")
+		// Pending invitations have no User.ID yet; their roles are granted to the invitation,
+		// which is keyed by membership ID.
+		var ur *v2.Resource
+		if user.User.ID == "" {
+			ur, err = invitationResource(user, accountResourceID(accountID))
+			if err != nil {
+				return nil, nil, wrapError(err, "failed to create invitation resource")
+			}
+		} else {
+			accUser := cloudflare.AccountMember{
+				User: cloudflare.AccountMemberUserDetails{
+					ID:        user.User.ID,
+					FirstName: user.User.FirstName,
+					LastName:  user.User.LastName,
+					Email:     user.User.Email,
+				},
+			}
+			ur, err = userResource(accUser, accountScopedID(r.accountId, accountID, accUser.User.ID), accountResourceID(accountID))
+			if err != nil {
+				return nil, nil, wrapError(err, "failed to create user resource")
+			}
 		}
 
 		gr := grant.NewGrant(resource, roleMemberEntitlement, ur.Id)
 		annos := annotations.Annotations(gr.Annotations)
 		v1Identifier := &v2.V1Identifier{
-			Id: V1GrantID(V1MembershipEntitlementID(roleId), user.ID),
+			Id: V1GrantID(V1MembershipEntitlementID(resource.Id.Resource), user.ID),
 		}
 		annos.Update(v1Identifier)
 		gr.Annotations = annos
 		rv = append(rv, gr)
+
+		for _, permission := range permissions {
+			rv = append(rv, grant.NewGrant(resource, permission, ur.Id, grant.WithAnnotation(&v2.GrantImmutable{})))
+		}
 	}
 
-	return rv, &rs.SyncOpResults{NextPageToken: nextPage}, nil
+	return rv, &rs.SyncOpResults{}, nil
 }
 
 func (r *roleResourceType) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
-	var (
-		err    error
-		userId = principal.Id.Resource
-		roleId = entitlement.Resource.Id.Resource
-	)
+	accountID, roleId := parseAccountScopedID(r.accountId, entitlement.Resource.Id.Resource)
 	l := ctxzap.Extract(ctx)
-	if principal.Id.ResourceType != resourceTypeUser.Id {
+	if principal.Id.ResourceType != resourceTypeUser.Id && principal.Id.ResourceType != resourceTypeInvitation.Id {
 		l.Warn(
-			"baton-cloudflare: only users can be granted role membership",
+			"baton-cloudflare: only users and invitations can be granted role membership",
 			zap.String("principal_type", principal.Id.ResourceType),
 			zap.String("principal_id", principal.Id.Resource),
 		)
-		return nil, fmt.Errorf("baton-cloudflare: only users can be granted role membership")
+		return nil, fmt.Errorf("baton-cloudflare: only users and invitations can be granted role membership")
+	}
+	if !isRoleMemberEntitlement(entitlement) {
+		return nil, fmt.Errorf("baton-cloudflare: role permission entitlements are read-only; grant the role membership instead")
 	}
 
-	// The member ID is written to the resource-level profile during sync; rs.GetProfile
-	// falls back to the deprecated trait profile for resources synced before the move,
-	// and an API lookup covers anything still missing it.
-	memberId, found := rs.GetProfileStringValue(rs.GetProfile(principal), memberIdProfileKey)
-	if !found || memberId == "" {
-		var err error
-		memberId, err = findMemberIDByUserID(ctx, r.client, r.accountId, userId)
-		if err != nil {
-			return nil, err
-		}
+	memberId, err := memberIDForPrincipal(ctx, r.client, accountID, principal)
+	if err != nil {
+		return nil, err
 	}
 
-	account, err := r.GetAccountMember(ctx, r.accountId, memberId)
+	account, err := r.GetAccountMember(ctx, accountID, memberId)
 	if err != nil {
 		return nil, fmt.Errorf("baton-cloudflare: failed to get account member for grant: %w", err)
 	}
@@ -268,7 +394,7 @@ func (r *roleResourceType) Grant(ctx context.Context, principal *v2.Resource, en
 		})
 	}
 
-	member, err := r.UpdateAccountMember(ctx, r.accountId, memberId, cloudflare.AccountMember{
+	member, err := r.UpdateAccountMember(ctx, accountID, memberId, cloudflare.AccountMember{
 		Roles: roles,
 	})
 	err = getError(err)
@@ -288,110 +414,42 @@ func (r *roleResourceType) Grant(ctx context.Context, principal *v2.Resource, en
 // Modify an account member
 // https://developers.cloudflare.com/api/operations/account-members-update-member
 func (r *roleResourceType) UpdateAccountMember(ctx context.Context, accountID, memberID string, accountMemberRoles cloudflare.AccountMember) (*cloudflare.AccountMember, error) {
-	var (
-		accountMemberListResponse = &Response{}
-		body                      struct {
-			Roles []roles
-		}
-	)
+	var body struct {
+		Roles []roles
+	}
 	for _, role := range accountMemberRoles.Roles {
 		body.Roles = append(body.Roles, roles{
 			ID: role.ID,
 		})
 	}
 
-	httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
-	if err != nil {
-		return nil, err
-	}
-
-	if accountID == "" {
-		return nil, ErrMissingAccountID
-	}
-
-	r.httpClient = uhttp.NewBaseHttpClient(httpClient)
-	endpointUrl := fmt.Sprintf("%s/accounts/%s/members/%s", r.client.BaseURL, accountID, memberID)
-	uri, err := url.Parse(endpointUrl)
-	if err != nil {
-		return nil, err
-	}
-
-	opts := []uhttp.RequestOption{
-		uhttp.WithJSONBody(body),
-		uhttp.WithAcceptJSONHeader(),
-	}
-	if r.client.APIToken != "" {
-		opts = append(opts, uhttp.WithBearerToken(r.client.APIToken))
-	}
-	if r.emailId != "" {
-		opts = append(opts, uhttp.WithHeader(XAuthEmailHeaderKey, r.emailId))
-	}
-	if r.client.APIKey != "" {
-		opts = append(opts, uhttp.WithHeader(XAuthKeyHeaderKey, r.client.APIKey))
-	}
-	req, err := r.httpClient.NewRequest(ctx,
-		http.MethodPut,
-		uri,
-		opts...,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := r.httpClient.Do(req, uhttp.WithJSONResponse(&accountMemberListResponse))
-	if err != nil {
-		ce := &CloudflareError{
-			ErrorMessage:     err.Error(),
-			ErrorDescription: err.Error(),
-			ErrorLink:        endpointUrl,
-		}
-		if resp != nil {
-			ce.ErrorCode = resp.StatusCode
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				ce.ErrorSummary = fmt.Sprintf("Error reading response body %s", err.Error())
-				return nil, ce
-			}
-
-			ce.ErrorSummary = string(bodyBytes)
-		}
-
-		return nil, ce
-	}
-
-	defer resp.Body.Close()
-	return &accountMemberListResponse.Result, nil
+	return putAccountMember(ctx, r.client, r.emailId, accountID, memberID, body)
 }
 
 func (r *roleResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
 	l := ctxzap.Extract(ctx)
 	entitlement := grant.Entitlement
 	principal := grant.Principal
-	if principal.Id.ResourceType != resourceTypeUser.Id {
+	if principal.Id.ResourceType != resourceTypeUser.Id && principal.Id.ResourceType != resourceTypeInvitation.Id {
 		l.Warn(
-			"baton-cloudflare: only users can have role membership revoked",
+			"baton-cloudflare: only users and invitations can have role membership revoked",
 			zap.String("principal_type", principal.Id.ResourceType),
 			zap.String("principal_id", principal.Id.Resource),
 		)
-		return nil, fmt.Errorf("baton-cloudflare: only users can have role membership revoked")
+		return nil, fmt.Errorf("baton-cloudflare: only users and invitations can have role membership revoked")
+	}
+	if !isRoleMemberEntitlement(entitlement) {
+		return nil, fmt.Errorf("baton-cloudflare: role permission entitlements are read-only; revoke the role membership instead")
 	}
 
-	userId := principal.Id.Resource
-	roleId := entitlement.Resource.Id.Resource
+	accountID, roleId := parseAccountScopedID(r.accountId, entitlement.Resource.Id.Resource)
 
-	// The member ID is written to the resource-level profile during sync; rs.GetProfile
-	// falls back to the deprecated trait profile for resources synced before the move,
-	// and an API lookup covers anything still missing it.
-	memberId, found := rs.GetProfileStringValue(rs.GetProfile(principal), memberIdProfileKey)
-	if !found || memberId == "" {
-		var err error
-		memberId, err = findMemberIDByUserID(ctx, r.client, r.accountId, userId)
-		if err != nil {
-			return nil, err
-		}
+	memberId, err := memberIDForPrincipal(ctx, r.client, accountID, principal)
+	if err != nil {
+		return nil, err
 	}
 
-	account, err := r.GetAccountMember(ctx, r.accountId, memberId)
+	account, err := r.GetAccountMember(ctx, accountID, memberId)
 	if err != nil {
 		return nil, err
 	}
@@ -417,7 +475,7 @@ func (r *roleResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotat
 		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
 	}
 
-	member, err := r.UpdateAccountMember(ctx, r.accountId, memberId, cloudflare.AccountMember{
+	member, err := r.UpdateAccountMember(ctx, accountID, memberId, cloudflare.AccountMember{
 		Roles: roles,
 	})
 	err = getError(err)
@@ -0,0 +1,105 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleResourcePermissions(t *testing.T) {
+	role := cloudflare.AccountRole{
+		ID:   "role-1",
+		Name: "Administrator Read Only",
+		Permissions: map[string]cloudflare.AccountRolePermission{
+			"zones":   {Read: true},
+			"dns":     {Read: true, Edit: true},
+			"billing": {},
+		},
+	}
+
+	assert.Equal(t, []string{"dns:read", "dns:edit", "zones:read"}, rolePermissionSlugs(role.Permissions))
+
+	resource, err := roleResource(role, resourceTypeRole, role.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dns:read", "dns:edit", "zones:read"}, rolePermissionSlugsFromProfile(resource))
+	assert.Equal(t, role.Permissions, rolePermissionsFromProfile(resource))
+
+	matrix := resource.GetProfile().GetFields()[rolePermissionsProfileKey].GetStructValue().GetFields()
+	assert.True(t, matrix["zones"].GetStructValue().GetFields()[rolePermissionRead].GetBoolValue())
+	assert.False(t, matrix["zones"].GetStructValue().GetFields()[rolePermissionEdit].GetBoolValue())
+	assert.Contains(t, matrix, "billing")
+}
+
+func TestRoleResourceWithoutPermissions(t *testing.T) {
+	resource, err := roleResource(cloudflare.AccountRole{ID: "role-1", Name: "Custom"}, resourceTypeRole, "role-1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, rolePermissionSlugsFromProfile(resource))
+}
+
+func TestIsRoleMemberEntitlement(t *testing.T) {
+	resource, err := roleResource(cloudflare.AccountRole{ID: "role-1", Name: "Custom"}, resourceTypeRole, "role-1", nil)
+	require.NoError(t, err)
+
+	assert.True(t, isRoleMemberEntitlement(ent.NewAssignmentEntitlement(resource, roleMemberEntitlement)))
+	assert.False(t, isRoleMemberEntitlement(ent.NewPermissionEntitlement(resource, "dns:edit")))
+}
+
+func TestRoleResourceSuperAdmin(t *testing.T) {
+	role := cloudflare.AccountRole{
+		ID:   "discovered-role",
+		Name: "Super Administrator - All Privileges",
+		Permissions: map[string]cloudflare.AccountRolePermission{
+			"billing":      {Read: true, Edit: true},
+			"dns":          {Read: true, Edit: true},
+			"organization": {Read: true, Edit: true},
+		},
+	}
+	require.True(t, isSuperAdminRole(role))
+	assert.True(t, isSuperAdminRole(cloudflare.AccountRole{ID: SuperAdminRoleId}))
+	assert.False(t, isSuperAdminRole(cloudflare.AccountRole{ID: "role-1", Name: "Administrator"}))
+
+	// A custom role named like the built-in one is not privileged unless its permissions are.
+	assert.False(t, isSuperAdminRole(cloudflare.AccountRole{ID: "role-2", Name: "Super Administrator (Custom)"}))
+	assert.False(t, isSuperAdminRole(cloudflare.AccountRole{
+		ID:   "role-3",
+		Name: "Super Administrator Lite",
+		Permissions: map[string]cloudflare.AccountRolePermission{
+			"billing": {Read: true},
+			"dns":     {Read: true, Edit: true},
+		},
+	}))
+
+	resource, err := roleResource(role, resourceTypeRole, role.ID, nil)
+	require.NoError(t, err)
+
+	assert.True(t, resource.GetProfile().GetFields()[rolePrivilegedProfileKey].GetBoolValue())
+
+	riskFactor := &v2.RiskFactor{}
+	annos := annotations.Annotations(resource.GetAnnotations())
+	ok, err := annos.Pick(riskFactor)
+	require.NoError(t, err)
+	require.True(t, ok, "expected a RiskFactor on the super admin role")
+	assert.Equal(t, v2.RiskFactor_SEVERITY_CRITICAL, riskFactor.GetSeverity())
+}
+
+// Pending invitations are granted roles by membership ID, so Grant/Revoke must not
+// try to resolve them through the (still empty) Cloudflare user ID.
+func TestMemberIDForInvitationPrincipal(t *testing.T) {
+	member := cloudflare.AccountMember{
+		ID:     "member-1",
+		Status: "pending",
+		User:   cloudflare.AccountMemberUserDetails{Email: "invitee@example.com"},
+	}
+	principal, err := invitationResource(member, accountResourceID("acct-1"))
+	require.NoError(t, err)
+
+	memberID, err := memberIDForPrincipal(context.Background(), &cloudflare.API{}, "acct-1", principal)
+	require.NoError(t, err)
+	assert.Equal(t, "member-1", memberID)
+}
@@ -0,0 +1,163 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+// tokenPermissionGroupResourceType syncs the permission groups held by the account's API tokens.
+// They are distinct from the IAM permission groups assigned to members: token permission groups
+// come from a separate catalog, such as "Zone Read" or "DNS Write".
+type tokenPermissionGroupResourceType struct {
+	resourceType *v2.ResourceType
+	tokens       *apiTokenResourceType
+	accountId    string
+}
+
+func (o *tokenPermissionGroupResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+func tokenPermissionGroupResource(pg cloudflare.APITokenPermissionGroups, resourceID string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	scopes := make([]interface{}, 0, len(pg.Scopes))
+	for _, scope := range pg.Scopes {
+		scopes = append(scopes, scope)
+	}
+
+	profile := map[string]interface{}{
+		"permission_group_id":   pg.ID,
+		"permission_group_name": pg.Name,
+		"scopes":                scopes,
+	}
+
+	return rs.NewResource(
+		apiTokenPermissionGroupName(pg),
+		resourceTypeTokenPermissionGroup,
+		resourceID,
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithResourceProfile(profile),
+	)
+}
+
+// List returns each permission group held by at least one of the account's API tokens. The
+// groups repeat across tokens, so every token is read in one pass and de-duplicated.
+func (o *tokenPermissionGroupResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	tokens, err := o.tokens.accountAPITokensSnapshot(ctx, opts.Session, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seen := make(map[string]bool)
+	var rv []*v2.Resource
+	for _, token := range tokens {
+		for _, policy := range token.Policies {
+			for _, pg := range policy.PermissionGroups {
+				if seen[pg.ID] {
+					continue
+				}
+				seen[pg.ID] = true
+
+				resource, err := tokenPermissionGroupResource(pg, accountScopedID(o.accountId, accountID, pg.ID), parentResourceID)
+				if err != nil {
+					return nil, nil, err
+				}
+				rv = append(rv, resource)
+			}
+		}
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// Entitlements returns the allow and deny entitlements, matching the effect of the token policy
+// that includes the permission group. Token policies are managed in Cloudflare, so both are
+// read-only.
+func (o *tokenPermissionGroupResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
+	rv := []*v2.Entitlement{
+		ent.NewPermissionEntitlement(
+			resource,
+			policyAccessAllow,
+			ent.WithGrantableTo(resourceTypeAPIToken),
+			ent.WithDisplayName(fmt.Sprintf("%s Allowed", resource.DisplayName)),
+			ent.WithDescription(fmt.Sprintf("Is allowed %s by an API token policy", resource.DisplayName)),
+			ent.WithAnnotation(&v2.EntitlementImmutable{}),
+		),
+		ent.NewPermissionEntitlement(
+			resource,
+			policyAccessDeny,
+			ent.WithGrantableTo(resourceTypeAPIToken),
+			ent.WithDisplayName(fmt.Sprintf("%s Denied", resource.DisplayName)),
+			ent.WithDescription(fmt.Sprintf("Is denied %s by an API token policy", resource.DisplayName)),
+			ent.WithAnnotation(&v2.EntitlementImmutable{}),
+		),
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// Grants grants the allow or deny entitlement to every API token with a policy of that effect
+// including the permission group. The resources the policy covers are in the token's profile.
+func (o *tokenPermissionGroupResourceType) Grants(ctx context.Context, resource *v2.Resource, opts rs.SyncOpAttrs) ([]*v2.Grant, *rs.SyncOpResults, error) {
+	accountID, permissionGroupID := parseAccountScopedID(o.accountId, resource.Id.Resource)
+	tokens, err := o.tokens.accountAPITokensSnapshot(ctx, opts.Session, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rv []*v2.Grant
+	for _, token := range tokens {
+		for _, effect := range apiTokenPermissionGroupEffects(token.APIToken, permissionGroupID) {
+			tokenResourceID := &v2.ResourceId{
+				ResourceType: resourceTypeAPIToken.Id,
+				Resource:     accountScopedID(o.accountId, accountID, token.ID),
+			}
+			rv = append(rv, grant.NewGrant(resource, effect, tokenResourceID, grant.WithAnnotation(&v2.GrantImmutable{})))
+		}
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// apiTokenPermissionGroupEffects returns the distinct effects, allow or deny, of the token's
+// policies that include the permission group.
+func apiTokenPermissionGroupEffects(token cloudflare.APIToken, permissionGroupID string) []string {
+	var rv []string
+	for _, policy := range token.Policies {
+		effect := strings.ToLower(policy.Effect)
+		if effect != policyAccessAllow && effect != policyAccessDeny {
+			continue
+		}
+		if slices.Contains(rv, effect) {
+			continue
+		}
+		for _, pg := range policy.PermissionGroups {
+			if pg.ID == permissionGroupID {
+				rv = append(rv, effect)
+				break
+			}
+		}
+	}
+
+	return rv
+}
+
+func tokenPermissionGroupBuilder(client *cloudflare.API, accountId, emailId string) *tokenPermissionGroupResourceType {
+	return &tokenPermissionGroupResourceType{
+		resourceType: resourceTypeTokenPermissionGroup,
+		tokens:       apiTokenBuilder(client, accountId, emailId),
+		accountId:    accountId,
+	}
+}
@@ -0,0 +1,55 @@
+package connector
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const apiTokenPoliciesJSON = `{
+	"id": "token-1",
+	"name": "deploy",
+	"policies": [
+		{
+			"effect": "allow",
+			"resources": {"com.cloudflare.api.account.zone.*": "*"},
+			"permission_groups": [{"id": "pg-zone-edit", "name": "Zone Write"}, {"id": "pg-dns-edit", "name": "DNS Write"}]
+		},
+		{
+			"effect": "deny",
+			"resources": {"com.cloudflare.api.account.acct-1": {"com.cloudflare.api.account.zone.zone-1": "*"}},
+			"permission_groups": [{"id": "pg-dns-edit", "name": "DNS Write"}]
+		}
+	]
+}`
+
+func TestAPITokenPolicyProfile(t *testing.T) {
+	var token cloudflare.APIToken
+	require.NoError(t, json.Unmarshal([]byte(apiTokenPoliciesJSON), &token))
+
+	profile := apiTokenPolicyProfile(token)
+	assert.Equal(t, []interface{}{
+		"allow Zone Write, DNS Write on all zones",
+		"deny DNS Write on zone zone-1 in account acct-1",
+	}, profile["policies"])
+	assert.Equal(t, []interface{}{"Zone Write", "DNS Write"}, profile["permission_groups"])
+	assert.Equal(t, []interface{}{"all zones", "zone zone-1 in account acct-1"}, profile["resources"])
+	assert.Equal(t, true, profile["wildcard_resource"])
+
+	resource, err := apiTokenResource(accountAPIToken{APIToken: token}, token.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "deploy", resource.GetProfile().AsMap()["token_name"])
+}
+
+func TestAPITokenPermissionGroupEffects(t *testing.T) {
+	var token cloudflare.APIToken
+	require.NoError(t, json.Unmarshal([]byte(apiTokenPoliciesJSON), &token))
+
+	assert.Equal(t, []string{policyAccessAllow}, apiTokenPermissionGroupEffects(token, "pg-zone-edit"))
+	assert.Equal(t, []string{policyAccessAllow, policyAccessDeny}, apiTokenPermissionGroupEffects(token, "pg-dns-edit"))
+	assert.Empty(t, apiTokenPermissionGroupEffects(token, "pg-other"))
+	assert.Equal(t, "all accounts", describeAPITokenScope("com.cloudflare.api.account.*"))
+}
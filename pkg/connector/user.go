@@ -21,29 +21,35 @@ type UserResourceType struct {
 	resourceType *v2.ResourceType
 	client       *cloudflare.API
 	accountId    string
+	// revokeAccessSessionsOnDelete signs removed users out of Access as part of Delete.
+	revokeAccessSessionsOnDelete bool
 }
 
 func (o *UserResourceType) ResourceType(_ context.Context) *v2.ResourceType {
 	return o.resourceType
 }
 
-func userResource(member cloudflare.AccountMember) (*v2.Resource, error) {
+// userResource builds an account member. A user can belong to several accounts, so members of
+// accounts other than the configured one carry an account-scoped resource ID.
+func userResource(member cloudflare.AccountMember, resourceID string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
 	user := member.User
 	firstName := user.FirstName
 	lastName := user.LastName
 	status := cases.Title(language.English).String(member.Status)
 	profile := map[string]interface{}{
-		"login":            user.Email,
-		"first_name":       firstName,
-		"last_name":        lastName,
-		"email":            user.Email,
-		"status":           status,
-		memberIdProfileKey: member.ID,
+		"login":                             user.Email,
+		"first_name":                        firstName,
+		"last_name":                         lastName,
+		"email":                             user.Email,
+		"status":                            status,
+		memberIdProfileKey:                  member.ID,
+		"two_factor_authentication_enabled": user.TwoFactorAuthenticationEnabled,
 	}
 
 	userTraits := []rs.UserTraitOption{
 		rs.WithUserLogin(user.Email),
 		rs.WithEmail(user.Email, true),
+		rs.WithMFAStatus(&v2.UserTrait_MFAStatus{MfaEnabled: user.TwoFactorAuthenticationEnabled}),
 	}
 
 	displayName := user.FirstName
@@ -54,8 +60,9 @@ func userResource(member cloudflare.AccountMember) (*v2.Resource, error) {
 	resource, err := rs.NewUserResource(
 		displayName,
 		resourceTypeUser,
-		user.ID,
+		resourceID,
 		userTraits,
+		rs.WithParentResourceID(parentResourceID),
 		rs.WithResourceProfile(profile),
 		rs.WithResourceStatus(memberResourceStatus(member.Status), status),
 	)
@@ -78,19 +85,17 @@ func memberResourceStatus(memberStatus string) v2.Status_ResourceStatus {
 	return v2.Status_RESOURCE_STATUS_DISABLED
 }
 
-func (o *UserResourceType) List(ctx context.Context, _ *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
-	page, err := convertPageToken(opts.PageToken.Token)
-	if err != nil {
-		return nil, nil, fmt.Errorf("baton-cloudflare: invalid page token error")
+func (o *UserResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
 	}
 
-	pageOpts := cloudflare.PaginationOptions{Page: page}
-	users, resp, err := o.client.AccountMembers(ctx, o.accountId, pageOpts)
+	users, err := accountMembersSnapshot(ctx, o.client, opts.Session, accountID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("baton-cloudflare: could not retrieve users: %w", err)
 	}
 
-	nextPage := convertNextPageToken(resp.Page, len(users))
 	rv := make([]*v2.Resource, 0, len(users))
 	for _, user := range users {
 		// Pending invitations have no User.ID yet; they are listed by the invitation resource type.
@@ -98,14 +103,14 @@ func (o *UserResourceType) List(ctx context.Context, _ *v2.ResourceId, opts rs.S
 			continue
 		}
 
-		userResource, err := userResource(user)
+		userResource, err := userResource(user, accountScopedID(o.accountId, accountID, user.User.ID), parentResourceID)
 		if err != nil {
 			return nil, nil, err
 		}
 		rv = append(rv, userResource)
 	}
 
-	return rv, &rs.SyncOpResults{NextPageToken: nextPage}, nil
+	return rv, &rs.SyncOpResults{}, nil
 }
 
 func (o *UserResourceType) Entitlements(_ context.Context, _ *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
@@ -129,6 +134,7 @@ func (o *UserResourceType) CreateAccountCapabilityDetails(_ context.Context) (*v
 // Cloudflare uses an invitation model — the user receives an email and must accept before gaining access.
 // The profile may include a "roles" field ([]interface{} of role ID strings) to assign initial roles.
 // At least one role ID is required by the Cloudflare API.
+// The profile may include an "account_id" field to invite into an account other than the configured one.
 func (o *UserResourceType) CreateAccount(
 	ctx context.Context,
 	accountInfo *v2.AccountInfo,
@@ -139,12 +145,25 @@ func (o *UserResourceType) CreateAccount(
 		return nil, nil, nil, err
 	}
 
-	roleIDs := getRoleIDsFromProfile(accountInfo)
+	accountID := getAccountIDFromProfile(accountInfo)
+	if accountID == "" {
+		accountID = o.accountId
+	}
+	if accountID == "" {
+		return nil, nil, nil, ErrMissingAccountID
+	}
+
+	// Role resources outside the configured account carry an account-scoped ID.
+	var roleIDs []string
+	for _, id := range getRoleIDsFromProfile(accountInfo) {
+		_, roleID := parseAccountScopedID(accountID, id)
+		roleIDs = append(roleIDs, roleID)
+	}
 	if len(roleIDs) == 0 {
 		return nil, nil, nil, fmt.Errorf("baton-cloudflare: at least one role ID is required to invite an account member")
 	}
 
-	member, err := o.client.CreateAccountMember(ctx, cloudflare.AccountIdentifier(o.accountId), cloudflare.CreateAccountMemberParams{
+	member, err := o.client.CreateAccountMember(ctx, cloudflare.AccountIdentifier(accountID), cloudflare.CreateAccountMemberParams{
 		EmailAddress: email,
 		Roles:        roleIDs,
 		Status:       "pending",
@@ -168,7 +187,7 @@ func (o *UserResourceType) CreateAccount(
 	}
 
 	var resource *v2.Resource
-	resource, err = invitationResource(member)
+	resource, err = invitationResource(member, accountResourceID(accountID))
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("baton-cloudflare: failed to build invitation resource after invite: %w", err)
 	}
@@ -179,14 +198,23 @@ func (o *UserResourceType) CreateAccount(
 	}, nil, nil, nil
 }
 
-// Delete removes a user from the Cloudflare account.
-// The resource ID is the Cloudflare user UUID; the member ID is resolved via API lookup.
-func (o *UserResourceType) Delete(ctx context.Context, resourceId *v2.ResourceId, _ *v2.ResourceId) (annotations.Annotations, error) {
+// Delete removes a user from the Cloudflare account it was synced under. Without a parent account,
+// the account comes from the account-scoped resource ID, or is the configured one.
+// The resource ID carries the Cloudflare user UUID; the member ID is resolved via API lookup.
+func (o *UserResourceType) Delete(ctx context.Context, resourceId *v2.ResourceId, parentResourceID *v2.ResourceId) (annotations.Annotations, error) {
 	if resourceId.ResourceType != resourceTypeUser.Id {
 		return nil, fmt.Errorf("baton-cloudflare: invalid resource type for delete: %s", resourceId.ResourceType)
 	}
 
-	memberID, err := findMemberIDByUserID(ctx, o.client, o.accountId, resourceId.Resource)
+	accountID, userID := parseAccountScopedID(o.accountId, resourceId.Resource)
+	if parentAccountID, ok := accountIDFromParent(parentResourceID); ok {
+		accountID = parentAccountID
+	}
+	if accountID == "" {
+		return nil, ErrMissingAccountID
+	}
+
+	member, err := findMemberByUserID(ctx, o.client, nil, accountID, userID)
 	if err != nil {
 		if errors.Is(err, errMemberNotFound) {
 			return nil, nil
@@ -194,7 +222,14 @@ func (o *UserResourceType) Delete(ctx context.Context, resourceId *v2.ResourceId
 		return nil, err
 	}
 
-	err = o.client.DeleteAccountMember(ctx, o.accountId, memberID)
+	// Sessions are revoked before the membership is removed, so a failed revocation can be retried.
+	if o.revokeAccessSessionsOnDelete && member.User.Email != "" {
+		if err := revokeAccessSessions(ctx, o.client, accountID, member.User.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	err = o.client.DeleteAccountMember(ctx, accountID, member.ID)
 	if err != nil {
 		var notFound *cloudflare.NotFoundError
 		if errors.As(err, &notFound) {
@@ -206,10 +241,11 @@ func (o *UserResourceType) Delete(ctx context.Context, resourceId *v2.ResourceId
 	return nil, nil
 }
 
-func userBuilder(client *cloudflare.API, accountId string) *UserResourceType {
+func userBuilder(client *cloudflare.API, accountId string, revokeAccessSessionsOnDelete bool) *UserResourceType {
 	return &UserResourceType{
-		resourceType: resourceTypeUser,
-		client:       client,
-		accountId:    accountId,
+		resourceType:                 resourceTypeUser,
+		client:                       client,
+		accountId:                    accountId,
+		revokeAccessSessionsOnDelete: revokeAccessSessionsOnDelete,
 	}
 }
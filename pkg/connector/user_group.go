@@ -0,0 +1,362 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	groupMemberEntitlement = "member"
+	userGroupsPerPage      = 50
+)
+
+type groupResourceType struct {
+	resourceType *v2.ResourceType
+	client       *cloudflare.API
+	httpClient   *uhttp.BaseHttpClient
+	accountId    string
+	emailId      string
+}
+
+func (o *groupResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+// userGroup models an IAM user group returned by /accounts/{account_id}/iam/user_groups.
+type userGroup struct {
+	ID         string              `json:"id"`
+	Name       string              `json:"name"`
+	CreatedOn  *time.Time          `json:"created_on,omitempty"`
+	ModifiedOn *time.Time          `json:"modified_on,omitempty"`
+	Policies   []cloudflare.Policy `json:"policies,omitempty"`
+}
+
+// userGroupMember is a user group member; ID is the account membership ID, not the user ID.
+type userGroupMember struct {
+	ID     string `json:"id"`
+	Email  string `json:"email"`
+	Status string `json:"status"`
+}
+
+type userGroupListResponse struct {
+	Result     []userGroup               `json:"result"`
+	ResultInfo cloudflare.ResultInfo     `json:"result_info"`
+	Success    bool                      `json:"success"`
+	Errors     []cloudflare.ResponseInfo `json:"errors"`
+}
+
+type userGroupMemberListResponse struct {
+	Result     []userGroupMember         `json:"result"`
+	ResultInfo cloudflare.ResultInfo     `json:"result_info"`
+	Success    bool                      `json:"success"`
+	Errors     []cloudflare.ResponseInfo `json:"errors"`
+}
+
+type userGroupResponse struct {
+	Success bool                      `json:"success"`
+	Errors  []cloudflare.ResponseInfo `json:"errors"`
+}
+
+func userGroupResource(group userGroup, resourceID string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"group_id":   group.ID,
+		"group_name": group.Name,
+	}
+
+	displayName := group.Name
+	if displayName == "" {
+		displayName = group.ID
+	}
+
+	opts := []rs.ResourceOption{
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithResourceProfile(profile),
+	}
+	if group.CreatedOn != nil {
+		opts = append(opts, rs.WithResourceCreatedAt(*group.CreatedOn))
+	}
+
+	return rs.NewGroupResource(
+		displayName,
+		resourceTypeGroup,
+		resourceID,
+		nil,
+		opts...,
+	)
+}
+
+func (o *groupResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	page, err := convertPageToken(opts.PageToken.Token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: invalid page token: %w", err)
+	}
+
+	resp, err := o.listUserGroups(ctx, accountID, page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rv := make([]*v2.Resource, 0, len(resp.Result))
+	for _, group := range resp.Result {
+		gr, err := userGroupResource(group, accountScopedID(o.accountId, accountID, group.ID), parentResourceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		rv = append(rv, gr)
+	}
+
+	nextPage := convertNextPageToken(resp.ResultInfo.Page, len(resp.Result))
+
+	return rv, &rs.SyncOpResults{NextPageToken: nextPage}, nil
+}
+
+func (o *groupResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
+	rv := []*v2.Entitlement{
+		ent.NewAssignmentEntitlement(
+			resource,
+			groupMemberEntitlement,
+			ent.WithGrantableTo(resourceTypeUser),
+			ent.WithDisplayName(
+				fmt.Sprintf("%s Group Member", resource.DisplayName),
+			),
+			ent.WithDescription(
+				fmt.Sprintf("Is a member of the %s user group in Cloudflare", resource.DisplayName),
+			),
+		),
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// Grants maps the group's members onto user resources. Group members are identified by their
+// account membership ID, so the sync's member snapshot is used to translate them to user IDs.
+func (o *groupResourceType) Grants(ctx context.Context, resource *v2.Resource, opts rs.SyncOpAttrs) ([]*v2.Grant, *rs.SyncOpResults, error) {
+	accountID, groupID := parseAccountScopedID(o.accountId, resource.Id.Resource)
+
+	groupMembers, err := o.listAllUserGroupMembers(ctx, accountID, groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(groupMembers) == 0 {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	members, err := accountMembersSnapshot(ctx, o.client, opts.Session, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	membersByID := make(map[string]cloudflare.AccountMember, len(members))
+	for _, member := range members {
+		membersByID[member.ID] = member
+	}
+
+	var rv []*v2.Grant
+	for _, groupMember := range groupMembers {
+		member, ok := membersByID[groupMember.ID]
+		// Pending invitations have no User.ID yet.
+		if !ok || member.User.ID == "" {
+			continue
+		}
+
+		ur, err := userResource(member, accountScopedID(o.accountId, accountID, member.User.ID), accountResourceID(accountID))
+		if err != nil {
+			return nil, nil, wrapError(err, "failed to create user resource")
+		}
+		rv = append(rv, grant.NewGrant(resource, groupMemberEntitlement, ur.Id))
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+func (o *groupResourceType) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	if principal.Id.ResourceType != resourceTypeUser.Id {
+		return nil, fmt.Errorf("baton-cloudflare: only users can be granted group membership")
+	}
+
+	accountID, groupID := parseAccountScopedID(o.accountId, entitlement.Resource.Id.Resource)
+	memberId, err := memberIDForPrincipal(ctx, o.client, accountID, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	groupMembers, err := o.listAllUserGroupMembers(ctx, accountID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if slices.ContainsFunc(groupMembers, func(m userGroupMember) bool { return m.ID == memberId }) {
+		l.Warn(
+			"baton-cloudflare: user is already a member of this group",
+			zap.String("principal_id", principal.Id.String()),
+			zap.String("group_id", groupID),
+		)
+		return annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+
+	body := []struct {
+		ID string `json:"id"`
+	}{{ID: memberId}}
+	err = o.doUserGroupRequest(ctx, http.MethodPost, nil, body, nil, "accounts", accountID, "iam", "user_groups", groupID, "members")
+	if err != nil {
+		return nil, fmt.Errorf("baton-cloudflare: failed to add group member: %w", err)
+	}
+
+	return nil, nil
+}
+
+func (o *groupResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	principal := grant.Principal
+	if principal.Id.ResourceType != resourceTypeUser.Id {
+		return nil, fmt.Errorf("baton-cloudflare: only users can have group membership revoked")
+	}
+
+	accountID, groupID := parseAccountScopedID(o.accountId, grant.Entitlement.Resource.Id.Resource)
+	memberId, err := memberIDForPrincipal(ctx, o.client, accountID, principal)
+	if err != nil {
+		return nil, err
+	}
+
+	groupMembers, err := o.listAllUserGroupMembers(ctx, accountID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(groupMembers, func(m userGroupMember) bool { return m.ID == memberId }) {
+		l.Warn(
+			"baton-cloudflare: user is not a member of this group",
+			zap.String("principal_id", principal.Id.String()),
+			zap.String("group_id", groupID),
+		)
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	err = o.doUserGroupRequest(ctx, http.MethodDelete, nil, nil, nil, "accounts", accountID, "iam", "user_groups", groupID, "members", memberId)
+	if err != nil {
+		return nil, fmt.Errorf("baton-cloudflare: failed to remove group member: %w", err)
+	}
+
+	return nil, nil
+}
+
+// listUserGroups calls GET /accounts/{account_id}/iam/user_groups, which cloudflare-go does not cover.
+func (o *groupResourceType) listUserGroups(ctx context.Context, accountID string, page int) (*userGroupListResponse, error) {
+	var result userGroupListResponse
+	err := o.doUserGroupRequest(ctx, http.MethodGet, pageQuery(page, userGroupsPerPage), nil, &result, "accounts", accountID, "iam", "user_groups")
+	if err != nil {
+		return nil, fmt.Errorf("baton-cloudflare: failed to list user groups: %w", err)
+	}
+	if !result.Success {
+		return nil, responseError("list user groups", result.Errors)
+	}
+
+	return &result, nil
+}
+
+// listAllUserGroupMembers pages through GET /accounts/{account_id}/iam/user_groups/{group_id}/members.
+func (o *groupResourceType) listAllUserGroupMembers(ctx context.Context, accountID, groupID string) ([]userGroupMember, error) {
+	var rv []userGroupMember
+	for page := 1; ; page++ {
+		var result userGroupMemberListResponse
+		err := o.doUserGroupRequest(ctx, http.MethodGet, pageQuery(page, userGroupsPerPage), nil, &result, "accounts", accountID, "iam", "user_groups", groupID, "members")
+		if err != nil {
+			return nil, fmt.Errorf("baton-cloudflare: failed to list user group members: %w", err)
+		}
+		if !result.Success {
+			return nil, responseError("list user group members", result.Errors)
+		}
+
+		rv = append(rv, result.Result...)
+		if len(result.Result) == 0 || len(rv) >= result.ResultInfo.Total {
+			return rv, nil
+		}
+	}
+}
+
+func pageQuery(page, perPage int) url.Values {
+	q := url.Values{}
+	q.Set("page", strconv.Itoa(page))
+	q.Set("per_page", strconv.Itoa(perPage))
+
+	return q
+}
+
+// doUserGroupRequest sends a request to the IAM user group endpoints, which cloudflare-go does
+// not cover. Path elements are joined onto the API base URL.
+func (o *groupResourceType) doUserGroupRequest(ctx context.Context, method string, query url.Values, body, result interface{}, path ...string) error {
+	if o.httpClient == nil {
+		httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
+		if err != nil {
+			return fmt.Errorf("baton-cloudflare: failed to create http client: %w", err)
+		}
+		o.httpClient = uhttp.NewBaseHttpClient(httpClient)
+	}
+
+	endpointURL, err := url.JoinPath(o.client.BaseURL, path...)
+	if err != nil {
+		return fmt.Errorf("baton-cloudflare: failed to build endpoint url: %w", err)
+	}
+	uri, err := url.Parse(endpointURL)
+	if err != nil {
+		return fmt.Errorf("baton-cloudflare: failed to parse endpoint url: %w", err)
+	}
+	uri.RawQuery = query.Encode()
+
+	reqOpts := authRequestOptions(o.client, o.emailId)
+	if body != nil {
+		reqOpts = append(reqOpts, uhttp.WithJSONBody(body))
+	}
+
+	req, err := o.httpClient.NewRequest(ctx, method, uri, reqOpts...)
+	if err != nil {
+		return fmt.Errorf("baton-cloudflare: failed to create request: %w", err)
+	}
+
+	if result == nil {
+		result = &userGroupResponse{}
+	}
+	resp, err := o.httpClient.Do(req, uhttp.WithJSONResponse(result))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// responseError turns the errors of an unsuccessful Cloudflare API response into an error.
+func responseError(operation string, errs []cloudflare.ResponseInfo) error {
+	if len(errs) > 0 {
+		return fmt.Errorf("baton-cloudflare: %s failed: %s (code %d)", operation, errs[0].Message, errs[0].Code)
+	}
+
+	return fmt.Errorf("baton-cloudflare: %s failed: unknown error", operation)
+}
+
+func groupBuilder(client *cloudflare.API, accountId, emailId string) *groupResourceType {
+	return &groupResourceType{
+		resourceType: resourceTypeGroup,
+		client:       client,
+		accountId:    accountId,
+		emailId:      emailId,
+	}
+}
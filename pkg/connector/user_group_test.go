@@ -0,0 +1,44 @@
+package connector
+
+import (
+	"encoding/json"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserGroupResource(t *testing.T) {
+	group := userGroup{ID: "group-1", Name: "SRE"}
+
+	resource, err := userGroupResource(group, accountScopedID("acct-1", "acct-2", group.ID), accountResourceID("acct-2"))
+	require.NoError(t, err)
+	assert.Equal(t, "acct-2/group-1", resource.GetId().GetResource())
+	assert.Equal(t, resourceTypeGroup.GetId(), resource.GetId().GetResourceType())
+	assert.Equal(t, "SRE", resource.GetDisplayName())
+	assert.Equal(t, "acct-2", resource.GetParentResourceId().GetResource())
+
+	groupTrait := &v2.GroupTrait{}
+	annos := annotations.Annotations(resource.GetAnnotations())
+	ok, err := annos.Pick(groupTrait)
+	require.NoError(t, err)
+	require.True(t, ok, "expected a GroupTrait on the group resource")
+	assert.Equal(t, "group-1", resource.GetProfile().GetFields()["group_id"].GetStringValue())
+}
+
+func TestUserGroupMemberListResponse(t *testing.T) {
+	body := `{
+		"success": true,
+		"errors": [],
+		"result": [{"id": "member-1", "email": "jane@example.com", "status": "accepted"}],
+		"result_info": {"page": 1, "per_page": 50, "count": 1, "total_count": 1}
+	}`
+
+	var resp userGroupMemberListResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	require.Len(t, resp.Result, 1)
+	assert.Equal(t, "member-1", resp.Result[0].ID)
+	assert.Equal(t, 1, resp.ResultInfo.Total)
+}
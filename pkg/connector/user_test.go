@@ -1,6 +1,7 @@
 package connector
 
 import (
+	"context"
 	"testing"
 
 	"github.com/cloudflare/cloudflare-go"
@@ -33,7 +34,7 @@ func TestUserResourceStatus(t *testing.T) {
 				},
 			}
 
-			resource, err := userResource(member)
+			resource, err := userResource(member, member.User.ID, nil)
 			require.NoError(t, err)
 
 			assert.Equal(t, tc.expectedStatus, resource.GetStatus().GetStatus())
@@ -56,7 +57,7 @@ func TestUserResourceProfile(t *testing.T) {
 		},
 	}
 
-	resource, err := userResource(member)
+	resource, err := userResource(member, member.User.ID, nil)
 	require.NoError(t, err)
 
 	memberID, found := rs.GetProfileStringValue(resource.GetProfile(), memberIdProfileKey)
@@ -67,3 +68,57 @@ func TestUserResourceProfile(t *testing.T) {
 	require.True(t, found)
 	assert.Equal(t, member.User.Email, email)
 }
+
+func TestUserResourceTwoFactor(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		member := cloudflare.AccountMember{
+			ID:     "member-1",
+			Status: "accepted",
+			User: cloudflare.AccountMemberUserDetails{
+				ID:                             "user-1",
+				Email:                          "someone@example.com",
+				TwoFactorAuthenticationEnabled: enabled,
+			},
+		}
+
+		resource, err := userResource(member, member.User.ID, nil)
+		require.NoError(t, err)
+
+		userTrait, err := rs.GetUserTrait(resource)
+		require.NoError(t, err)
+		assert.Equal(t, enabled, userTrait.GetMfaStatus().GetMfaEnabled())
+		assert.Equal(t, enabled, resource.GetProfile().GetFields()["two_factor_authentication_enabled"].GetBoolValue())
+	}
+}
+
+// Members of accounts other than the configured one are scoped to their account, so the same
+// user synced under two accounts yields two resources.
+func TestUserResourceAccountScopedID(t *testing.T) {
+	member := cloudflare.AccountMember{
+		ID:     "member-2",
+		Status: "accepted",
+		User:   cloudflare.AccountMemberUserDetails{ID: "user-1", Email: "someone@example.com"},
+	}
+
+	resource, err := userResource(member, accountScopedID("acct-1", "acct-2", member.User.ID), accountResourceID("acct-2"))
+	require.NoError(t, err)
+	assert.Equal(t, "acct-2/user-1", resource.GetId().GetResource())
+
+	accountID, userID := parseAccountScopedID("acct-1", resource.GetId().GetResource())
+	assert.Equal(t, "acct-2", accountID)
+	assert.Equal(t, "user-1", userID)
+}
+
+func TestUserDeleteMissingAccount(t *testing.T) {
+	o := userBuilder(nil, "", false)
+
+	_, err := o.Delete(context.Background(), &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: "user-1"}, nil)
+	assert.ErrorIs(t, err, ErrMissingAccountID)
+}
+
+func TestMemberIDForPrincipalMissingAccount(t *testing.T) {
+	principal := &v2.Resource{Id: &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: "user-1"}}
+
+	_, err := memberIDForPrincipal(context.Background(), nil, "", principal)
+	assert.ErrorIs(t, err, ErrMissingAccountID)
+}
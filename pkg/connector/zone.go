@@ -0,0 +1,352 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/session"
+	ent "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sdk/pkg/types/sessions"
+)
+
+const (
+	// zoneScopeKey is the resource scope Cloudflare uses for permission groups that apply to a single zone.
+	zoneScopeKey = "com.cloudflare.api.account.zone"
+
+	zoneSnapshotKeyPrefix = "zones"
+)
+
+func zoneSnapshotKey(zoneID string) string {
+	return fmt.Sprintf("%s:%s", zoneSnapshotKeyPrefix, zoneID)
+}
+
+type zoneResourceType struct {
+	resourceType *v2.ResourceType
+	client       *cloudflare.API
+	accountId    string
+}
+
+func (o *zoneResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+func zoneResource(zone cloudflare.Zone, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"zone_id":   zone.ID,
+		"zone_name": zone.Name,
+		"status":    zone.Status,
+		"paused":    zone.Paused,
+		"plan":      zone.Plan.Name,
+	}
+
+	return rs.NewResource(
+		zone.Name,
+		resourceTypeZone,
+		zone.ID,
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithResourceProfile(profile),
+		rs.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: resourceTypeZoneRole.Id}),
+	)
+}
+
+func (o *zoneResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	accountID, ok := accountIDFromParent(parentResourceID)
+	if !ok {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	// ListZonesContext paginates on its own and returns every zone in the account.
+	zones, err := o.client.ListZonesContext(ctx, cloudflare.WithZoneFilters("", accountID, ""))
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: could not retrieve zones: %w", err)
+	}
+
+	rv := make([]*v2.Resource, 0, len(zones.Result))
+	snapshot := make(map[string]cloudflare.Zone, len(zones.Result))
+	for _, zone := range zones.Result {
+		zr, err := zoneResource(zone, parentResourceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		rv = append(rv, zr)
+		snapshot[zoneSnapshotKey(zone.ID)] = cloudflare.Zone{ID: zone.ID, Name: zone.Name, Account: zone.Account}
+	}
+
+	// The zone roles listed under each zone need its name and account; keeping them in the
+	// session saves a zone lookup per zone.
+	if opts.Session != nil && len(snapshot) > 0 {
+		if err := session.SetManyJSON(ctx, opts.Session, snapshot); err != nil {
+			return nil, nil, fmt.Errorf("baton-cloudflare: failed to store zone snapshot: %w", err)
+		}
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// zoneSnapshot returns the zone as stored by the zone List during the sync, or looks it up when
+// it is not stored.
+func zoneSnapshot(ctx context.Context, client *cloudflare.API, ss sessions.SessionStore, zoneID string) (cloudflare.Zone, error) {
+	if ss != nil {
+		zone, found, err := session.GetJSON[cloudflare.Zone](ctx, ss, zoneSnapshotKey(zoneID))
+		if err != nil {
+			return cloudflare.Zone{}, fmt.Errorf("baton-cloudflare: failed to read zone snapshot: %w", err)
+		}
+		if found {
+			return zone, nil
+		}
+	}
+
+	zone, err := client.ZoneDetails(ctx, zoneID)
+	if err != nil {
+		return cloudflare.Zone{}, fmt.Errorf("baton-cloudflare: could not retrieve zone %s: %w", zoneID, err)
+	}
+
+	return zone, nil
+}
+
+func (o *zoneResourceType) Entitlements(_ context.Context, _ *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
+	return nil, nil, nil
+}
+
+func (o *zoneResourceType) Grants(_ context.Context, _ *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Grant, *rs.SyncOpResults, error) {
+	return nil, nil, nil
+}
+
+func zoneBuilder(client *cloudflare.API, accountId string) *zoneResourceType {
+	return &zoneResourceType{
+		resourceType: resourceTypeZone,
+		client:       client,
+		accountId:    accountId,
+	}
+}
+
+// zoneRoleResourceType models a zone-scoped permission group (a "domain scoped role") on one zone.
+// Membership is expressed as member policies whose resource groups cover the zone, so grants and
+// provisioning both go through the member's policies rather than its legacy roles.
+type zoneRoleResourceType struct {
+	resourceType *v2.ResourceType
+	client       *cloudflare.API
+	accountId    string
+	emailId      string
+}
+
+func (o *zoneRoleResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+func zoneRoleResourceID(zoneID, permissionGroupID string) string {
+	return zoneID + policyIDSeparator + permissionGroupID
+}
+
+func parseZoneRoleResourceID(id string) (string, string, error) {
+	zoneID, permissionGroupID, ok := strings.Cut(id, policyIDSeparator)
+	if !ok || zoneID == "" || permissionGroupID == "" {
+		return "", "", fmt.Errorf("baton-cloudflare: invalid zone role ID %q", id)
+	}
+
+	return zoneID, permissionGroupID, nil
+}
+
+// resourceGroupCoversZone reports whether a policy resource group includes the zone, either as
+// an inline zone scope (cloudflare-go's NewResourceGroupForZone) or as one of its scope objects.
+func resourceGroupCoversZone(rg cloudflare.ResourceGroup, zoneID string) bool {
+	zoneKey := fmt.Sprintf("%s.%s", zoneScopeKey, zoneID)
+	if rg.Scope.Key == zoneKey {
+		return true
+	}
+	for _, obj := range rg.Scope.ScopeObjects {
+		if obj.Key == zoneKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isZonePermissionGroup reports whether a permission group can be bound to a zone.
+func isZonePermissionGroup(pg cloudflare.PermissionGroup) bool {
+	return permissionGroupHasScope(pg, zoneScopeKey)
+}
+
+func zoneRoleResource(zone cloudflare.Zone, pg cloudflare.PermissionGroup, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"account_id":            zone.Account.ID,
+		"zone_id":               zone.ID,
+		"zone_name":             zone.Name,
+		"permission_group_id":   pg.ID,
+		"permission_group_name": pg.Name,
+	}
+
+	return rs.NewRoleResource(
+		fmt.Sprintf("%s (%s)", pg.Name, zone.Name),
+		resourceTypeZoneRole,
+		zoneRoleResourceID(zone.ID, pg.ID),
+		nil,
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithResourceProfile(profile),
+	)
+}
+
+func (o *zoneRoleResourceType) List(ctx context.Context, parentResourceID *v2.ResourceId, opts rs.SyncOpAttrs) ([]*v2.Resource, *rs.SyncOpResults, error) {
+	if parentResourceID == nil || parentResourceID.ResourceType != resourceTypeZone.Id {
+		return nil, &rs.SyncOpResults{}, nil
+	}
+
+	zone, err := zoneSnapshot(ctx, o.client, opts.Session, parentResourceID.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	permissionGroups, err := accountPermissionGroupsSnapshot(ctx, o.client, opts.Session, zone.Account.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rv []*v2.Resource
+	for _, pg := range permissionGroups {
+		if !isZonePermissionGroup(pg) {
+			continue
+		}
+		zr, err := zoneRoleResource(zone, pg, parentResourceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		rv = append(rv, zr)
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+func (o *zoneRoleResourceType) Entitlements(_ context.Context, resource *v2.Resource, _ rs.SyncOpAttrs) ([]*v2.Entitlement, *rs.SyncOpResults, error) {
+	rv := []*v2.Entitlement{
+		ent.NewAssignmentEntitlement(
+			resource,
+			roleMemberEntitlement,
+			ent.WithGrantableTo(resourceTypeUser),
+			ent.WithDisplayName(
+				fmt.Sprintf("%s Member Role", resource.DisplayName),
+			),
+			ent.WithDescription(
+				fmt.Sprintf("Has the %s zone-scoped role in Cloudflare", resource.DisplayName),
+			),
+		),
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+func (o *zoneRoleResourceType) Grants(ctx context.Context, resource *v2.Resource, opts rs.SyncOpAttrs) ([]*v2.Grant, *rs.SyncOpResults, error) {
+	zoneID, permissionGroupID, err := parseZoneRoleResourceID(resource.Id.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accountID, found := rs.GetProfileStringValue(resource.GetProfile(), "account_id")
+	if !found || accountID == "" {
+		accountID = o.accountId
+	}
+
+	members, err := accountMembersSnapshot(ctx, o.client, opts.Session, accountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-cloudflare: could not retrieve account members: %w", err)
+	}
+
+	coversZone := func(rg cloudflare.ResourceGroup) bool {
+		return resourceGroupCoversZone(rg, zoneID)
+	}
+	var rv []*v2.Grant
+	for _, member := range members {
+		// Pending invitations have no User.ID yet.
+		if member.User.ID == "" {
+			continue
+		}
+		if !hasAllowBinding(member.Policies, permissionGroupID, coversZone) {
+			continue
+		}
+
+		ur, err := userResource(member, accountScopedID(o.accountId, accountID, member.User.ID), accountResourceID(accountID))
+		if err != nil {
+			return nil, nil, wrapError(err, "failed to create user resource")
+		}
+		rv = append(rv, grant.NewGrant(resource, roleMemberEntitlement, ur.Id))
+	}
+
+	return rv, &rs.SyncOpResults{}, nil
+}
+
+// zoneAccountID returns the account that owns the zone role's zone; memberships and their policies
+// live there. The zone role records it in its profile, so the zone is only looked up for
+// resources that lack it.
+func (o *zoneRoleResourceType) zoneAccountID(ctx context.Context, resource *v2.Resource, zoneID string) (string, error) {
+	if accountID, ok := rs.GetProfileStringValue(rs.GetProfile(resource), "account_id"); ok && accountID != "" {
+		return accountID, nil
+	}
+
+	zone, err := zoneSnapshot(ctx, o.client, nil, zoneID)
+	if err != nil {
+		return "", err
+	}
+
+	return zone.Account.ID, nil
+}
+
+// Grant adds an allow policy binding the permission group to the zone.
+func (o *zoneRoleResourceType) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	if principal.Id.ResourceType != resourceTypeUser.Id {
+		return nil, fmt.Errorf("baton-cloudflare: only users can be granted zone role membership")
+	}
+
+	zoneID, permissionGroupID, err := parseZoneRoleResourceID(entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	accountID, err := o.zoneAccountID(ctx, entitlement.Resource, zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	return grantMemberPolicy(
+		ctx, o.client, o.emailId, accountID, principal, permissionGroupID,
+		cloudflare.NewResourceGroupForZone(cloudflare.Zone{ID: zoneID}),
+		func(rg cloudflare.ResourceGroup) bool {
+			return resourceGroupCoversZone(rg, zoneID)
+		},
+	)
+}
+
+func (o *zoneRoleResourceType) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	principal := grant.Principal
+	if principal.Id.ResourceType != resourceTypeUser.Id {
+		return nil, fmt.Errorf("baton-cloudflare: only users can have zone role membership revoked")
+	}
+
+	zoneID, permissionGroupID, err := parseZoneRoleResourceID(grant.Entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	accountID, err := o.zoneAccountID(ctx, grant.Entitlement.Resource, zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	return revokeMemberPolicy(ctx, o.client, o.emailId, accountID, principal, permissionGroupID, func(rg cloudflare.ResourceGroup) bool {
+		return resourceGroupCoversZone(rg, zoneID)
+	})
+}
+
+func zoneRoleBuilder(client *cloudflare.API, accountId, emailId string) *zoneRoleResourceType {
+	return &zoneRoleResourceType{
+		resourceType: resourceTypeZoneRole,
+		client:       client,
+		accountId:    accountId,
+		emailId:      emailId,
+	}
+}
@@ -0,0 +1,70 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/session"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZoneRoleResourceID(t *testing.T) {
+	id := zoneRoleResourceID("zone-1", "pg-1")
+
+	zoneID, permissionGroupID, err := parseZoneRoleResourceID(id)
+	require.NoError(t, err)
+	assert.Equal(t, "zone-1", zoneID)
+	assert.Equal(t, "pg-1", permissionGroupID)
+
+	_, _, err = parseZoneRoleResourceID("zone-1")
+	assert.Error(t, err)
+}
+
+func TestResourceGroupCoversZone(t *testing.T) {
+	inline := cloudflare.NewResourceGroupForZone(cloudflare.Zone{ID: "zone-1"})
+	assert.True(t, resourceGroupCoversZone(inline, "zone-1"))
+	assert.False(t, resourceGroupCoversZone(inline, "zone-2"))
+
+	// Resource groups managed through the IAM API list zones as scope objects under the account scope.
+	managed := cloudflare.ResourceGroup{
+		ID: "rg-1",
+		Scope: cloudflare.Scope{
+			Key: "com.cloudflare.api.account.acct-1",
+			ScopeObjects: []cloudflare.ScopeObject{
+				{Key: "com.cloudflare.api.account.zone.zone-1"},
+				{Key: "com.cloudflare.api.account.zone.zone-3"},
+			},
+		},
+	}
+	assert.True(t, resourceGroupCoversZone(managed, "zone-3"))
+	assert.False(t, resourceGroupCoversZone(managed, "zone-2"))
+}
+
+// Zone roles are built from the zone stored by the zone List and the account's permission group
+// snapshot, without looking either up again.
+func TestZoneRoleListFromSession(t *testing.T) {
+	ctx := context.Background()
+	ss := newMapSessionStore()
+	zone := cloudflare.Zone{ID: "zone-1", Name: "example.com", Account: cloudflare.Account{ID: "acct-1"}}
+	require.NoError(t, session.SetJSON(ctx, ss, zoneSnapshotKey(zone.ID), zone))
+	require.NoError(t, session.SetJSON(ctx, ss, permissionGroupSnapshotKeyPrefix+":acct-1", []cloudflare.PermissionGroup{
+		{ID: "pg-zone", Name: "DNS Admin", Meta: map[string]string{permissionGroupScopeKey: zoneScopeKey}},
+		{ID: "pg-account", Name: "Billing", Meta: map[string]string{permissionGroupScopeKey: accountScopeKey}},
+	}))
+
+	o := zoneRoleBuilder(nil, "acct-1", "")
+	resources, _, err := o.List(ctx, &v2.ResourceId{ResourceType: resourceTypeZone.Id, Resource: zone.ID}, rs.SyncOpAttrs{Session: ss})
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, zoneRoleResourceID("zone-1", "pg-zone"), resources[0].GetId().GetResource())
+	assert.Equal(t, "DNS Admin (example.com)", resources[0].GetDisplayName())
+
+	// Grant and Revoke read the zone's account from the zone role profile.
+	accountID, err := o.zoneAccountID(ctx, resources[0], zone.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "acct-1", accountID)
+}